@@ -0,0 +1,306 @@
+/*
+* File Name:	annotate.go
+* Description:  把人脸、五官定位、人脸检索、文字识别和车辆识别的结果画到图片上, 便于调试
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//标注颜色
+var (
+	ColorFace     = color.RGBA{0, 200, 0, 255}
+	ColorIdentify = color.RGBA{0, 120, 255, 255}
+	ColorText     = color.RGBA{230, 0, 180, 255}
+	ColorCar      = color.RGBA{255, 140, 0, 255}
+	ColorLogo     = color.RGBA{255, 220, 0, 255}
+)
+
+//landmarkColors 五官定位点按部位的颜色, 键同FaceShape.Polygons
+var landmarkColors = map[string]color.RGBA{
+	"face_profile":  {0, 220, 220, 255},
+	"left_eye":      {255, 60, 60, 255},
+	"right_eye":     {255, 60, 60, 255},
+	"left_eyebrow":  {255, 160, 0, 255},
+	"right_eyebrow": {255, 160, 0, 255},
+	"mouth":         {220, 0, 220, 255},
+	"nose":          {60, 120, 255, 255},
+}
+
+//Canvas 标注画布. 结果坐标先按记录的Transform映射回原图, 再画到按EXIF方向摆正的图片上.
+//文字只能显示ASCII字符, 其它字符(如中文)画成方框
+type Canvas struct {
+	Image *image.RGBA
+	t     Transform //原图到画布的变换
+	scale int       //字体放大倍数和线宽
+}
+
+//NewCanvas 解码img作为画布, url图片需要SetURLFetch
+func (y *Youtu) NewCanvas(img Image) (c *Canvas, err error) {
+	m, t, err := y.decodeImage("", img)
+	if err != nil {
+		return
+	}
+	return newCanvas(m, t), nil
+}
+
+//NewCanvasFromImage 复制m作为画布, 结果坐标按m的坐标处理
+func NewCanvasFromImage(m image.Image) *Canvas {
+	return newCanvas(toRGBA(m), IdentityTransform())
+}
+
+func newCanvas(m *image.RGBA, t Transform) *Canvas {
+	//长边每600像素放大一倍, 大图上的字和线不至于太细
+	return &Canvas{Image: m, t: t, scale: maxInt(1, maxInt(m.Bounds().Dx(), m.Bounds().Dy())/600)}
+}
+
+//toCanvas 结果坐标到画布坐标的变换, rspT为请求时记录的原图到发送图片的变换
+func (c *Canvas) toCanvas(rspT *Transform) Transform {
+	return resultTransform(c.t, rspT)
+}
+
+//clipSegment 用Liang-Barsky算法把线段ab截取到r内, 完全在r外时返回false
+func clipSegment(a, b Point, r Rect) (Point, Point, bool) {
+	t0, t1 := 0.0, 1.0
+	dx, dy := b.X-a.X, b.Y-a.Y
+	for _, e := range [4][2]float64{{-dx, a.X - r.Min.X}, {dx, r.Max.X - a.X}, {-dy, a.Y - r.Min.Y}, {dy, r.Max.Y - a.Y}} {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return a, b, false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			if t > t1 {
+				return a, b, false
+			}
+			t0 = math.Max(t0, t)
+		} else {
+			if t < t0 {
+				return a, b, false
+			}
+			t1 = math.Min(t1, t)
+		}
+	}
+	return lerp(a, b, t0), lerp(a, b, t1), true
+}
+
+//finite 是否都是有限值
+func finite(vs ...float64) bool {
+	for _, v := range vs {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+//line 画线宽为scale的线段. 先截取到图片范围(留出线宽)内再逐点画,
+//端点远在图片外时不会多走无用的点, 含NaN或无穷大坐标的线段不画
+func (c *Canvas) line(a, b Point, col color.RGBA) {
+	if !finite(a.X, a.Y, b.X, b.Y, b.X-a.X, b.Y-a.Y) {
+		return
+	}
+	a, b, ok := clipSegment(a, b, RectFrom(c.Image.Bounds()).Inset(-float64(c.scale)))
+	if !ok {
+		return
+	}
+	x0, y0, x1, y1 := int(math.Floor(a.X)), int(math.Floor(a.Y)), int(math.Floor(b.X)), int(math.Floor(b.Y))
+	dx, dy := x1-x0, y1-y0
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	lo, hi := -(c.scale-1)/2, c.scale/2+1
+	err := dx - dy
+	for {
+		fillRect(c.Image, image.Rect(x0+lo, y0+lo, x0+hi, y0+hi), col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x0 += sx
+		}
+		if e2 < dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+//polyline 依次连接p的顶点, closed时首尾相连
+func (c *Canvas) polyline(p Polygon, col color.RGBA, closed bool) {
+	for i := 0; i+1 < len(p); i++ {
+		c.line(p[i], p[i+1], col)
+	}
+	if closed && len(p) > 2 {
+		c.line(p[len(p)-1], p[0], col)
+	}
+}
+
+//label 在画布坐标的框b上方(放不下时在框内)画带底色的文字
+func (c *Canvas) label(b Rect, text string, col color.RGBA) {
+	if text == "" {
+		return
+	}
+	w, h := textSize(text, c.scale)
+	pad := c.scale
+	x, y := roundInt(b.Min.X), roundInt(b.Min.Y)-h-2*pad
+	if y < 0 {
+		y = roundInt(b.Min.Y)
+	}
+	x = maxInt(0, minInt(x, c.Image.Bounds().Dx()-w-2*pad))
+	fillRect(c.Image, image.Rect(x, y, x+w+2*pad, y+h+2*pad), col)
+	fg := color.RGBA{255, 255, 255, 255}
+	if int(col.R)*299+int(col.G)*587+int(col.B)*114 > 150000 {
+		fg = color.RGBA{0, 0, 0, 255}
+	}
+	drawText(c.Image, x+pad, y+pad, text, c.scale, fg)
+}
+
+//Box 画结果坐标中的框r(按rspT映射, 旋转时画成四边形)和标签
+func (c *Canvas) Box(r Rect, rspT *Transform, text string, col color.RGBA) {
+	p := c.toCanvas(rspT).MapPolygon(r.Polygon())
+	//框的边是像素边界, 顶点向中心移动半个像素后落在框内侧的像素上
+	center := centroid(p)
+	inner := make(Polygon, len(p))
+	for i, pt := range p {
+		inner[i] = Pt(pt.X+math.Copysign(0.5, center.X-pt.X), pt.Y+math.Copysign(0.5, center.Y-pt.Y))
+	}
+	c.polyline(inner, col, true)
+	c.label(p.Bounds(), text, col)
+}
+
+//faceLabel 性别、年龄、表情和眼镜
+func faceLabel(f Face) string {
+	gender := "F"
+	if f.Gender >= 50 {
+		gender = "M"
+	}
+	s := fmt.Sprintf("%s %d expr %d", gender, f.Age, f.Expression)
+	if f.Glass {
+		s += " glass"
+	}
+	return s
+}
+
+//DetectFace 画人脸框, 标注性别(M/F)、年龄和表情
+func (c *Canvas) DetectFace(rsp DetectFaceRsp) {
+	for _, f := range rsp.Face {
+		c.Box(f.Rect(), rsp.Transform, faceLabel(f), ColorFace)
+	}
+}
+
+//FaceShape 按部位用不同颜色画88个五官定位点和轮廓线
+func (c *Canvas) FaceShape(rsp FaceShapeRsp) {
+	t := c.toCanvas(rsp.Transform)
+	for _, s := range rsp.FaceShape {
+		for part, p := range s.Polygons() {
+			col := landmarkColors[part]
+			p = t.MapPolygon(p)
+			//脸型轮廓不闭合, 其它部位是闭合的轮廓
+			c.polyline(p, col, part != "face_profile")
+			for _, pt := range p {
+				x, y := roundInt(pt.X), roundInt(pt.Y)
+				fillRect(c.Image, image.Rect(x-c.scale, y-c.scale, x+c.scale+1, y+c.scale+1), col)
+			}
+		}
+	}
+}
+
+//MultiFaceIdentify 画检索到的人脸框, 标注置信度最高的候选人
+func (c *Canvas) MultiFaceIdentify(rsp MultiFaceIdentifyRsp) {
+	for _, r := range rsp.Results {
+		text := "unknown"
+		if len(r.Candidates) > 0 {
+			best := r.Candidates[0]
+			for _, cand := range r.Candidates[1:] {
+				if cand.Confidence > best.Confidence {
+					best = cand
+				}
+			}
+			text = fmt.Sprintf("%s %.1f", best.PersonID, best.Confidence)
+		}
+		c.Box(r.FaceRect.Rect(), rsp.Transform, text, ColorIdentify)
+	}
+}
+
+//OcrItems 画文字框和识别的文字, 有字段名时标注为"字段名: 文字"
+func (c *Canvas) OcrItems(items []ItemContent, rspT *Transform) {
+	for _, it := range items {
+		text := it.Itemstring
+		if it.Item != "" {
+			text = it.Item + ": " + text
+		}
+		c.Box(it.Rect(), rspT, text, ColorText)
+	}
+}
+
+//GeneralOcr 同OcrItems
+func (c *Canvas) GeneralOcr(rsp GeneralOcrRsp) {
+	c.OcrItems(rsp.Items, rsp.Transform)
+}
+
+//CarClassify 画车辆框和车标框, 车辆框标注置信度最高的品牌、车型和颜色
+func (c *Canvas) CarClassify(rsp CarClassifyRsp) {
+	text := ""
+	if len(rsp.Tags) > 0 {
+		best := rsp.Tags[0]
+		for _, tag := range rsp.Tags[1:] {
+			if tag.Confidence > best.Confidence {
+				best = tag
+			}
+		}
+		text = strings.Join(strings.Fields(fmt.Sprintf("%s %s %s %.2f", best.Brand, best.Type, best.Color, best.Confidence)), " ")
+	}
+	c.Box(rsp.CarRect(), rsp.Transform, text, ColorCar)
+	for _, r := range rsp.LogoRects() {
+		c.Box(r, rsp.Transform, "", ColorLogo)
+	}
+}
+
+//Encode 用enc编码画布, 如PNGEncoder()或JPEGEncoder(90)
+func (c *Canvas) Encode(w io.Writer, enc ImageEncoder) error {
+	return enc(w, c.Image)
+}
+
+//Save 保存画布, 扩展名为.jpg或.jpeg时保存为JPEG, 否则为PNG
+func (c *Canvas) Save(path string) (err error) {
+	enc := PNGEncoder()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		enc = JPEGEncoder(DefaultNormalizeQuality)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	if err = c.Encode(f, enc); err != nil {
+		f.Close()
+		return
+	}
+	return f.Close()
+}
@@ -0,0 +1,121 @@
+/*
+* File Name:	annotate_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func whiteImage(w, h int) *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range m.Pix {
+		m.Pix[i] = 255
+	}
+	return m
+}
+
+func TestDrawText(t *testing.T) {
+	m := whiteImage(40, 20)
+	black := color.RGBA{0, 0, 0, 255}
+	drawText(m, 1, 1, "I|", 2, black)
+	//'I'的中间一列是实心的竖线, 每个点放大为2x2
+	for y := 1; y < 15; y++ {
+		if m.RGBAAt(5, y) != black || m.RGBAAt(6, y) != black {
+			t.Errorf("pixel (5, %d) of 'I' = %v\n", y, m.RGBAAt(5, y))
+		}
+	}
+	if m.RGBAAt(1, 7) == black || m.RGBAAt(1, 16) == black {
+		t.Errorf("'I' drawn outside its glyph\n")
+	}
+	if w, h := textSize("I|", 2); w != 22 || h != 14 {
+		t.Errorf("textSize = %d, %d\n", w, h)
+	}
+	//中文画成方框
+	m = whiteImage(10, 10)
+	drawText(m, 0, 0, "中", 1, black)
+	if m.RGBAAt(0, 0) != black || m.RGBAAt(4, 6) != black || m.RGBAAt(2, 3) == black {
+		t.Errorf("missing glyph is not a hollow box\n")
+	}
+}
+
+func TestCanvas(t *testing.T) {
+	m := whiteImage(200, 100)
+	y := Init(AppSign{}, "")
+	c, err := y.NewCanvas(ImagePNG(m))
+	if err != nil {
+		t.Errorf("NewCanvas: %v\n", err)
+		return
+	}
+	//发送图片缩小为一半, 人脸框映射回原图后画在(60, 40)-(100, 80)
+	half := ScaleTransform(0.5, 0.5)
+	c.DetectFace(DetectFaceRsp{Transform: &half, Face: []Face{{X: 30, Y: 20, Width: 20, Height: 20, Gender: 90, Age: 30}}})
+	for _, p := range []image.Point{{60, 60}, {80, 40}, {99, 60}, {80, 79}} {
+		if c.Image.RGBAAt(p.X, p.Y) != ColorFace {
+			t.Errorf("face box pixel %v = %v\n", p, c.Image.RGBAAt(p.X, p.Y))
+		}
+	}
+	if c.Image.RGBAAt(80, 60) != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("face box is filled\n")
+	}
+	//标签在框上方
+	if c.Image.RGBAAt(60, 35) != ColorFace {
+		t.Errorf("label background = %v\n", c.Image.RGBAAt(60, 35))
+	}
+
+	c.CarClassify(CarClassifyRsp{CarCoord: CarCoordinate{X: 120, Y: 10, Width: 70, Height: 80}, LogoCoord: []CarCoordinate{{X: 140, Y: 50, Width: 20, Height: 10}}})
+	if c.Image.RGBAAt(120, 50) != ColorCar || c.Image.RGBAAt(150, 50) != ColorLogo {
+		t.Errorf("car boxes not drawn\n")
+	}
+	//车辆框同样按记录的变换映射回原图
+	cc := NewCanvasFromImage(whiteImage(100, 100))
+	cc.CarClassify(CarClassifyRsp{Transform: &half, CarCoord: CarCoordinate{X: 10, Y: 10, Width: 20, Height: 20}})
+	if cc.Image.RGBAAt(20, 40) != ColorCar || cc.Image.RGBAAt(10, 20) == ColorCar {
+		t.Errorf("car box ignores the recorded transform\n")
+	}
+
+	c.FaceShape(FaceShapeRsp{FaceShape: []FaceShape{{Nose: []pos{{10, 10}, {20, 10}, {20, 20}}}}})
+	if c.Image.RGBAAt(15, 10) != landmarkColors["nose"] || c.Image.RGBAAt(15, 15) != landmarkColors["nose"] {
+		t.Errorf("nose contour not drawn\n")
+	}
+
+	//端点远在图片外或不是有限值的线段只画图片内的部分, 不会长时间循环
+	lc := NewCanvasFromImage(whiteImage(20, 20))
+	lc.line(Pt(-1e12, -1e12), Pt(1e12, 1e12), ColorText)
+	lc.line(Pt(math.NaN(), 0), Pt(10, 10), ColorText)
+	lc.line(Pt(0, 15), Pt(math.Inf(1), 15), ColorText)
+	lc.line(Pt(-1e300, 5), Pt(-1e299, 5), ColorText)
+	if lc.Image.RGBAAt(10, 10) != ColorText || lc.Image.RGBAAt(15, 15) != ColorText || lc.Image.RGBAAt(10, 5) != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("clipped line not drawn correctly\n")
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"out.png", "out.JPG"} {
+		path := filepath.Join(dir, name)
+		if err := c.Save(path); err != nil {
+			t.Errorf("Save(%s): %v\n", name, err)
+			continue
+		}
+		data, _ := os.ReadFile(path)
+		var err error
+		if name == "out.png" {
+			_, err = png.Decode(bytes.NewReader(data))
+		} else {
+			_, err = jpeg.Decode(bytes.NewReader(data))
+		}
+		if err != nil {
+			t.Errorf("%s has the wrong format: %v\n", name, err)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+/*
+* File Name:	anonymize.go
+* Description:  人脸匿名化: 检测人脸后模糊、马赛克或遮挡, 可跳过已授权的个体, 并输出处理报告
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+//RedactMode 人脸区域的处理方式
+type RedactMode int
+
+const (
+	//RedactBlur 模糊
+	RedactBlur RedactMode = iota
+	//RedactPixelate 马赛克
+	RedactPixelate
+	//RedactMask 纯色遮挡
+	RedactMask
+)
+
+func (m RedactMode) String() string {
+	switch m {
+	case RedactBlur:
+		return "blur"
+	case RedactPixelate:
+		return "pixelate"
+	case RedactMask:
+		return "mask"
+	}
+	return "unknown"
+}
+
+const (
+	//DefaultAnonymizePadding 人脸框四周默认扩展的比例, 覆盖头发和下巴
+	DefaultAnonymizePadding = 0.2
+	//DefaultConsentConfidence 候选人置信度不低于该值才视为已授权的个体
+	DefaultConsentConfidence = 80
+)
+
+//AnonymizeOptions 匿名化选项
+type AnonymizeOptions struct {
+	Mode      RedactMode
+	Padding   float64         //人脸框四周扩展的比例(相对框的宽高), 为0时使用DefaultAnonymizePadding, 小于0时不扩展
+	Strength  int             //模糊半径或马赛克块大小(像素), 为0时按人脸大小选择
+	MaskColor color.RGBA      //RedactMask的颜色, 默认黑色
+	Tiled     bool            //用DetectFaceTiled检测, 适合人群照片
+	Tile      FaceTileOptions //Tiled时的分块选项
+
+	Consenting    []string //已授权的个体person_id, 识别为这些个体的人脸不处理
+	GroupID       string   //识别个体用的组, 与GroupIDs二选一, Consenting不为空时必填
+	GroupIDs      []string //识别个体用的组列表
+	MinConfidence float32  //候选人置信度下限, 默认DefaultConsentConfidence
+}
+
+//RedactedFace 一张人脸的处理情况
+type RedactedFace struct {
+	Face       Face    `json:"face"`                 //检测结果
+	Rect       Rect    `json:"rect"`                 //处理的区域, 为按EXIF方向摆正后的图片坐标
+	Redacted   bool    `json:"redacted"`             //是否已处理
+	PersonID   string  `json:"person_id,omitempty"`  //未处理时为识别出的已授权个体
+	Confidence float32 `json:"confidence,omitempty"` //识别的置信度
+}
+
+//AnonymizeReport 匿名化报告
+type AnonymizeReport struct {
+	Width       int            `json:"width"`  //输出图片的宽度
+	Height      int            `json:"height"` //输出图片的高度
+	Mode        string         `json:"mode"`
+	Faces       []RedactedFace `json:"faces"`
+	Redacted    int            `json:"redacted"`               //处理的人脸数
+	Kept        int            `json:"kept"`                   //因已授权而保留的人脸数
+	IdentifyErr string         `json:"identify_err,omitempty"` //个体识别失败的原因, 此时所有人脸都被处理
+}
+
+//Anonymize 检测img中的人脸并按o.Mode处理, 返回按EXIF方向摆正的图片和报告.
+//Consenting不为空时用MultiFaceIdentify识别个体, 与人脸框IoU超过0.5且置信度最高的候选人
+//是已授权个体的人脸不处理; 识别失败时所有人脸都被处理, 原因记录在报告中.
+//检测失败(Tiled时任一分块失败)时返回错误, 不输出图片, 以免漏掉失败分块中的人脸
+func (y *Youtu) Anonymize(img Image, o AnonymizeOptions) (out *image.RGBA, report AnonymizeReport, err error) {
+	if len(o.Consenting) > 0 {
+		if err = (MultiFaceIdentifyRequest{GroupID: o.GroupID, GroupIDs: o.GroupIDs, Image: img}).Validate(); err != nil {
+			return
+		}
+	}
+	if o.Padding == 0 {
+		o.Padding = DefaultAnonymizePadding
+	}
+	if o.Padding < 0 {
+		o.Padding = 0
+	}
+	if o.MinConfidence <= 0 {
+		o.MinConfidence = DefaultConsentConfidence
+	}
+	if o.MaskColor == (color.RGBA{}) {
+		o.MaskColor = color.RGBA{0, 0, 0, 255}
+	}
+
+	m, t, err := y.decodeImage("", img)
+	if err != nil {
+		return
+	}
+	var det DetectFaceRsp
+	if o.Tiled {
+		var tiled TiledDetectFaceRsp
+		tiled, err = y.DetectFaceTiled(img, o.Tile)
+		det = tiled.DetectFaceRsp
+		for _, tile := range tiled.Tiles {
+			if err != nil {
+				break
+			}
+			if tile.Err != nil {
+				err = fmt.Errorf("tile %v: %w", tile.Rect, tile.Err)
+			} else if tile.ErrorCode != 0 {
+				err = fmt.Errorf("tile %v: %w", tile.Rect, rspError("detectface", tile.ErrorCode, tile.ErrorMsg))
+			}
+		}
+	} else {
+		det, err = y.DetectFaceImage(img, false)
+	}
+	if err != nil {
+		return
+	}
+	if det.ErrorCode != 0 {
+		err = rspError("detectface", det.ErrorCode, det.ErrorMsg)
+		return
+	}
+
+	//已授权个体的人脸框, 画布坐标
+	var consented []RedactedFace
+	if len(o.Consenting) > 0 {
+		ids, ierr := y.MultiFaceIdentifyRequest(MultiFaceIdentifyRequest{GroupID: o.GroupID, GroupIDs: o.GroupIDs, Image: img})
+		if ierr == nil && ids.ErrorCode != 0 {
+			ierr = rspError("multifaceidentify", ids.ErrorCode, ids.ErrorMsg)
+		}
+		if ierr != nil {
+			report.IdentifyErr = ierr.Error()
+		} else {
+			it := resultTransform(t, ids.Transform)
+			for _, r := range ids.Results {
+				if len(r.Candidates) == 0 {
+					continue
+				}
+				best := r.Candidates[0]
+				for _, c := range r.Candidates[1:] {
+					if c.Confidence > best.Confidence {
+						best = c
+					}
+				}
+				if best.Confidence >= o.MinConfidence && contains(o.Consenting, best.PersonID) {
+					consented = append(consented, RedactedFace{Rect: it.MapRect(r.FaceRect.Rect()), PersonID: best.PersonID, Confidence: best.Confidence})
+				}
+			}
+		}
+	}
+
+	out = m
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	dt := resultTransform(t, det.Transform)
+	report.Width, report.Height, report.Mode = w, h, o.Mode.String()
+	for _, f := range det.Face {
+		box := dt.MapRect(f.Rect())
+		rf := RedactedFace{Face: f}
+		for _, c := range consented {
+			if box.IoU(c.Rect) > 0.5 {
+				rf.PersonID, rf.Confidence = c.PersonID, c.Confidence
+				break
+			}
+		}
+		dx, dy := box.Dx()*o.Padding, box.Dy()*o.Padding
+		//向外取整, 不漏掉边缘的像素
+		area := image.Rect(int(math.Floor(box.Min.X-dx)), int(math.Floor(box.Min.Y-dy)), int(math.Ceil(box.Max.X+dx)), int(math.Ceil(box.Max.Y+dy))).Intersect(m.Bounds())
+		rf.Rect = RectFrom(area)
+		if rf.PersonID == "" {
+			redact(m, area, o)
+			rf.Redacted = true
+			report.Redacted++
+		} else {
+			report.Kept++
+		}
+		report.Faces = append(report.Faces, rf)
+	}
+	return
+}
+
+//redact 按选项处理m中的区域r. 模糊和马赛克只使用区域内的像素, 区域外的内容不会混入
+func redact(m *image.RGBA, r image.Rectangle, o AnonymizeOptions) {
+	r = r.Intersect(m.Bounds())
+	if r.Empty() {
+		return
+	}
+	strength := o.Strength
+	switch o.Mode {
+	case RedactMask:
+		fillRect(m, r, o.MaskColor)
+	case RedactPixelate:
+		if strength <= 0 {
+			strength = maxInt(4, minInt(r.Dx(), r.Dy())/8)
+		}
+		for by := r.Min.Y; by < r.Max.Y; by += strength {
+			for bx := r.Min.X; bx < r.Max.X; bx += strength {
+				b := image.Rect(bx, by, bx+strength, by+strength).Intersect(r)
+				var sum [4]int
+				for y := b.Min.Y; y < b.Max.Y; y++ {
+					for x := b.Min.X; x < b.Max.X; x++ {
+						p := m.Pix[m.PixOffset(x, y):]
+						for c := 0; c < 4; c++ {
+							sum[c] += int(p[c])
+						}
+					}
+				}
+				n := b.Dx() * b.Dy()
+				fillRect(m, b, color.RGBA{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), uint8(sum[3] / n)})
+			}
+		}
+	default:
+		if strength <= 0 {
+			strength = maxInt(4, minInt(r.Dx(), r.Dy())/6)
+		}
+		//三次盒式模糊近似高斯模糊
+		for i := 0; i < 3; i++ {
+			boxBlur(m, r, strength)
+		}
+	}
+}
+
+//boxBlur 对区域r做半径为radius的盒式模糊, 先水平后垂直, 边缘按区域边界截断
+func boxBlur(m *image.RGBA, r image.Rectangle, radius int) {
+	w, h := r.Dx(), r.Dy()
+	prefix := make([]float64, 4*(maxInt(w, h)+1))
+	pass := func(n, lines int, at func(line, i int) int) {
+		for l := 0; l < lines; l++ {
+			//先求前缀和, 再写回窗口平均
+			for i := 0; i < n; i++ {
+				p := m.Pix[at(l, i):]
+				for c := 0; c < 4; c++ {
+					prefix[4*(i+1)+c] = prefix[4*i+c] + float64(p[c])
+				}
+			}
+			for i := 0; i < n; i++ {
+				lo, hi := maxInt(0, i-radius), minInt(n, i+radius+1)
+				p := m.Pix[at(l, i):]
+				for c := 0; c < 4; c++ {
+					p[c] = uint8(math.Round((prefix[4*hi+c] - prefix[4*lo+c]) / float64(hi-lo)))
+				}
+			}
+		}
+	}
+	pass(w, h, func(line, i int) int { return m.PixOffset(r.Min.X+i, r.Min.Y+line) })
+	pass(h, w, func(line, i int) int { return m.PixOffset(r.Min.X+line, r.Min.Y+i) })
+}
@@ -0,0 +1,121 @@
+/*
+* File Name:	anonymize_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+)
+
+//regionStats 区域内红色通道的最小值和最大值
+func regionStats(m *image.RGBA, r image.Rectangle) (lo, hi uint8) {
+	lo = 255
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			v := m.RGBAAt(x, y).R
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+	}
+	return
+}
+
+func TestAnonymize(t *testing.T) {
+	//两张"人脸"是黑白棋盘格, 背景为灰色
+	src := image.NewRGBA(image.Rect(0, 0, 300, 120))
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.RGBA{128, 128, 128, 255}
+			if (x >= 40 && x < 100 || x >= 200 && x < 260) && y >= 30 && y < 90 {
+				v := uint8(255 * ((x/5 + y/5) % 2))
+				c = color.RGBA{v, v, v, 255}
+			}
+			src.SetRGBA(x, y, c)
+		}
+	}
+	identifyFails := false
+	var detects int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path.Base(r.URL.Path) {
+		case "detectface":
+			//分块检测时第一个请求失败
+			if atomic.AddInt32(&detects, 1) == 1 {
+				fmt.Fprint(w, `{"errorcode":-1101,"errormsg":"detect failed"}`)
+				return
+			}
+			fmt.Fprint(w, `{"errorcode":0,"image_width":300,"image_height":120,"face":[{"x":40,"y":30,"width":60,"height":60},{"x":200,"y":30,"width":60,"height":60}]}`)
+		case "multifaceidentify":
+			if identifyFails {
+				fmt.Fprint(w, `{"errorcode":-1101,"errormsg":"group not found"}`)
+				return
+			}
+			fmt.Fprint(w, `{"errorcode":0,"results":[{"face_rect":{"x":202,"y":28,"width":58,"height":62},"candidates":[{"person_id":"alice","confidence":60},{"person_id":"bob","confidence":92}]}]}`)
+		}
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+	left, right := image.Rect(40, 30, 100, 90), image.Rect(200, 30, 260, 90)
+
+	//部分分块检测失败时不输出图片
+	if out, _, err := y.Anonymize(ImagePNG(src), AnonymizeOptions{Tiled: true, Tile: FaceTileOptions{TileSize: 160, Overlap: 40, Concurrency: 1}}); err == nil || out != nil {
+		t.Errorf("Anonymize with a failed tile = %v\n", err)
+	}
+
+	for _, mode := range []RedactMode{RedactBlur, RedactPixelate, RedactMask} {
+		out, report, err := y.Anonymize(ImagePNG(src), AnonymizeOptions{Mode: mode, Padding: -1})
+		if err != nil || report.Redacted != 2 || report.Mode != mode.String() {
+			t.Errorf("Anonymize(%v) = %+v, %v\n", mode, report, err)
+			continue
+		}
+		//棋盘格不再清晰可辨
+		if lo, hi := regionStats(out, left.Inset(8)); int(hi)-int(lo) > 160 {
+			t.Errorf("%v: face still has contrast %d-%d\n", mode, lo, hi)
+		}
+		//人脸外的像素不变
+		if out.RGBAAt(20, 20) != src.RGBAAt(20, 20) || out.RGBAAt(150, 60) != src.RGBAAt(150, 60) {
+			t.Errorf("%v: background changed\n", mode)
+		}
+	}
+	out, report, _ := y.Anonymize(ImagePNG(src), AnonymizeOptions{Mode: RedactMask, MaskColor: color.RGBA{255, 0, 0, 255}})
+	//默认扩展20%
+	if report.Faces[0].Rect != RectXYWH(28, 18, 84, 84) || out.RGBAAt(30, 20) != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("padded mask = %v, pixel %v\n", report.Faces[0].Rect, out.RGBAAt(30, 20))
+	}
+
+	//已授权的bob不处理, 置信度较低的候选人alice不起作用
+	o := AnonymizeOptions{Consenting: []string{"bob"}, GroupID: "staff"}
+	out, report, err := y.Anonymize(ImagePNG(src), o)
+	if err != nil || report.Redacted != 1 || report.Kept != 1 || report.Faces[1].PersonID != "bob" || report.Faces[1].Redacted {
+		t.Errorf("Anonymize with consent = %+v, %v\n", report, err)
+	}
+	if lo, hi := regionStats(out, right); lo != 0 || hi != 255 {
+		t.Errorf("consenting face was changed\n")
+	}
+	o.Consenting = []string{"alice"}
+	if _, report, _ = y.Anonymize(ImagePNG(src), o); report.Redacted != 2 {
+		t.Errorf("low-confidence candidate kept a face: %+v\n", report)
+	}
+	//识别失败时全部处理
+	identifyFails = true
+	o.Consenting = []string{"bob"}
+	if _, report, err = y.Anonymize(ImagePNG(src), o); err != nil || report.Redacted != 2 || report.IdentifyErr == "" {
+		t.Errorf("Anonymize when identify fails = %+v, %v\n", report, err)
+	}
+	if _, _, err = y.Anonymize(ImagePNG(src), AnonymizeOptions{Consenting: []string{"bob"}}); err == nil {
+		t.Errorf("Anonymize accepted Consenting without a group\n")
+	}
+}
@@ -0,0 +1,211 @@
+/*
+* File Name:	blur.go
+* Description:  本地清晰度估计(拉普拉斯方差), 在调用接口前拦截明显模糊的图片, 并可用FuzzyDetect校准阈值
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+const (
+	//DefaultBlurThreshold 清晰度低于该值视为模糊. 不同来源的图片差别很大, 建议用CalibrateBlur在自己的数据上校准
+	DefaultBlurThreshold = 100
+	//blurAnalysisSize 计算清晰度前把图片缩小到的长边, 使得分与原图分辨率无关
+	blurAnalysisSize = 512
+)
+
+//Sharpness 图片的清晰度: 长边缩小到512后灰度图拉普拉斯响应的方差, 越大越清晰
+func Sharpness(m image.Image) float64 {
+	src := toRGBA(m)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if long := maxInt(w, h); long > blurAnalysisSize {
+		w, h = fitSize(w, h, blurAnalysisSize)
+		src = resize(src, w, h)
+	}
+	if w < 3 || h < 3 {
+		return 0
+	}
+	gray := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := src.Pix[y*src.Stride+x*4:]
+			gray[y*w+x] = 0.299*float64(p[0]) + 0.587*float64(p[1]) + 0.114*float64(p[2])
+		}
+	}
+	var sum, sum2 float64
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			l := 4*gray[i] - gray[i-1] - gray[i+1] - gray[i-w] - gray[i+w]
+			sum += l
+			sum2 += l * l
+		}
+	}
+	n := float64((w - 2) * (h - 2))
+	mean := sum / n
+	return sum2/n - mean*mean
+}
+
+//ImageSharpness 在本地解码img并计算Sharpness, url图片需要SetURLFetch
+func (y *Youtu) ImageSharpness(img Image) (float64, error) {
+	m, _, err := y.decodeImage("", img)
+	if err != nil {
+		return 0, err
+	}
+	return Sharpness(m), nil
+}
+
+//BlurGateOptions 本地模糊拦截选项
+type BlurGateOptions struct {
+	Threshold float64  //清晰度低于该值的图片在发送前被拒绝, 为0时使用DefaultBlurThreshold
+	Endpoints []string //拦截的接口, 如"fuzzydetect", "newperson", "idcardocr", 为空时拦截所有接口
+}
+
+//SetBlurGate 开启本地模糊拦截, o为nil时关闭. 被拦截的图片返回ValidationError, 不发送请求.
+//只检查图片数据、ImageFromStd和SDK下载的url图片, 由服务器下载的url不检查
+func (y *Youtu) SetBlurGate(o *BlurGateOptions) {
+	var g *BlurGateOptions
+	if o != nil {
+		c := *o
+		c.Endpoints = append([]string(nil), o.Endpoints...)
+		if c.Threshold <= 0 {
+			c.Threshold = DefaultBlurThreshold
+		}
+		g = &c
+	}
+	y.shared.mu.Lock()
+	y.shared.blurGate = g
+	y.shared.mu.Unlock()
+}
+
+func (y *Youtu) blurGate(ifname string) *BlurGateOptions {
+	y.shared.mu.RLock()
+	g := y.shared.blurGate
+	y.shared.mu.RUnlock()
+	if g == nil || (len(g.Endpoints) > 0 && !contains(g.Endpoints, ifname)) {
+		return nil
+	}
+	return g
+}
+
+//checkBlur 按拦截选项检查图片, ImageFromStd的图片直接使用原图, 其它解码data
+func (y *Youtu) checkBlur(ifname string, img Image, data []byte) error {
+	g := y.blurGate(ifname)
+	if g == nil || img.noBlurGate {
+		return nil
+	}
+	m := img.std
+	if m == nil {
+		format, _, _ := inspectImage(data)
+		var err error
+		if m, err = decodeAny(format, data); err != nil {
+			//无法解码的图片交给后面的格式校验
+			return nil
+		}
+	}
+	if s := Sharpness(m); s < g.Threshold {
+		return withEndpoint(ifname, invalid("Image", fmt.Sprintf("too blurry: sharpness %.1f below %.1f", s, g.Threshold)))
+	}
+	return nil
+}
+
+//BlurSample 一张图片的本地清晰度和FuzzyDetect结果
+type BlurSample struct {
+	Sharpness       float64 //本地清晰度
+	Fuzzy           bool    //FuzzyDetect是否判为模糊
+	FuzzyConfidence float32 //FuzzyDetect的模糊参考值, 越大越模糊
+	Err             error   //解码或FuzzyDetect失败的原因, 失败的样本不参与校准
+}
+
+//BlurCalibration 本地清晰度与FuzzyDetect的对比结果
+type BlurCalibration struct {
+	Samples       []BlurSample
+	Threshold     float64 //与FuzzyDetect判断一致的图片最多的阈值
+	Accuracy      float64 //按Threshold拦截时与FuzzyDetect一致的比例
+	SafeThreshold float64 //不拦截任何FuzzyDetect判为清晰的图片的最大阈值
+	Rejected      float64 //按SafeThreshold可以在本地拦截的图片比例
+	Correlation   float64 //log(1+清晰度)与FuzzyConfidence的相关系数, 越接近-1本地估计越可靠
+}
+
+//CalibrateBlur 对每张图片计算本地清晰度并调用FuzzyDetect, 用结果校准拦截阈值.
+//校准请求不受SetBlurGate拦截
+func (y *Youtu) CalibrateBlur(images []Image) (c BlurCalibration) {
+	c.Samples = make([]BlurSample, len(images))
+	for i, img := range images {
+		s := &c.Samples[i]
+		if s.Sharpness, s.Err = y.ImageSharpness(img); s.Err != nil {
+			continue
+		}
+		img.noBlurGate = true
+		rsp, err := y.FuzzyDetectImage(img, "")
+		if err == nil && rsp.ErrorCode != 0 {
+			err = rspError("fuzzydetect", int(rsp.ErrorCode), rsp.ErrorMsg)
+		}
+		s.Fuzzy, s.FuzzyConfidence, s.Err = rsp.Fuzzy, rsp.FuzzyConfidence, err
+	}
+	c.fit()
+	return
+}
+
+//CalibrateBlurSamples 用已有样本(如多次CalibrateBlur的结果)校准阈值
+func CalibrateBlurSamples(samples []BlurSample) BlurCalibration {
+	c := BlurCalibration{Samples: samples}
+	c.fit()
+	return c
+}
+
+//fit 在样本的清晰度之间搜索阈值
+func (c *BlurCalibration) fit() {
+	var ok []BlurSample
+	for _, s := range c.Samples {
+		if s.Err == nil {
+			ok = append(ok, s)
+		}
+	}
+	if len(ok) == 0 {
+		return
+	}
+	sort.Slice(ok, func(i, j int) bool { return ok[i].Sharpness < ok[j].Sharpness })
+	//阈值取在相邻样本之间, 低于阈值判为模糊
+	candidates := []float64{0}
+	for i := 0; i+1 < len(ok); i++ {
+		candidates = append(candidates, (ok[i].Sharpness+ok[i+1].Sharpness)/2)
+	}
+	candidates = append(candidates, ok[len(ok)-1].Sharpness+1)
+	n := float64(len(ok))
+	c.Accuracy = -1
+	for _, thr := range candidates {
+		agree, rejected, falseReject := 0, 0, false
+		for _, s := range ok {
+			blurry := s.Sharpness < thr
+			if blurry == s.Fuzzy {
+				agree++
+			}
+			if blurry {
+				rejected++
+				falseReject = falseReject || !s.Fuzzy
+			}
+		}
+		if a := float64(agree) / n; a > c.Accuracy {
+			c.Threshold, c.Accuracy = thr, a
+		}
+		if !falseReject {
+			c.SafeThreshold, c.Rejected = thr, float64(rejected)/n
+		}
+	}
+
+	var sx, sy, sxx, syy, sxy float64
+	for _, s := range ok {
+		x, y := math.Log1p(s.Sharpness), float64(s.FuzzyConfidence)
+		sx, sy, sxx, syy, sxy = sx+x, sy+y, sxx+x*x, syy+y*y, sxy+x*y
+	}
+	if d := math.Sqrt((n*sxx - sx*sx) * (n*syy - sy*sy)); d > 0 {
+		c.Correlation = (n*sxy - sx*sy) / d
+	}
+}
@@ -0,0 +1,126 @@
+/*
+* File Name:	blur_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//blurredImage 生成随机灰度块纹理, 再做r次3x3均值模糊
+func blurredImage(w, h, r int) *image.RGBA {
+	rnd := rand.New(rand.NewSource(1))
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y += 4 {
+		for x := 0; x < w; x += 4 {
+			v := uint8(rnd.Intn(256))
+			for i := 0; i < 16; i++ {
+				if px, py := x+i%4, y+i/4; px < w && py < h {
+					copy(m.Pix[py*m.Stride+px*4:], []uint8{v, v, v, 255})
+				}
+			}
+		}
+	}
+	for ; r > 0; r-- {
+		out := image.NewRGBA(m.Bounds())
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				sum, n := 0, 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if px, py := x+dx, y+dy; px >= 0 && py >= 0 && px < w && py < h {
+							sum += int(m.Pix[py*m.Stride+px*4])
+							n++
+						}
+					}
+				}
+				v := uint8(sum / n)
+				copy(out.Pix[y*out.Stride+x*4:], []uint8{v, v, v, 255})
+			}
+		}
+		m = out
+	}
+	return m
+}
+
+func TestSharpness(t *testing.T) {
+	prev := -1.0
+	for _, r := range []int{8, 4, 2, 1, 0} {
+		s := Sharpness(blurredImage(200, 150, r))
+		if s <= prev {
+			t.Errorf("Sharpness with %d blur passes = %.1f, not above %.1f\n", r, s, prev)
+		}
+		prev = s
+	}
+	//长边缩小到512后计算, 得分与分辨率大致无关
+	small, big := Sharpness(blurredImage(512, 256, 2)), Sharpness(resize(blurredImage(512, 256, 2), 1024, 512))
+	if big < small*0.7 || big > small*1.3 {
+		t.Errorf("Sharpness depends on resolution: %.1f vs %.1f\n", small, big)
+	}
+}
+
+func TestBlurGate(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		data, _ := base64.StdEncoding.DecodeString(body["image"].(string))
+		m, _, _ := image.Decode(bytes.NewReader(data))
+		s := Sharpness(m)
+		fmt.Fprintf(w, `{"errorcode":0,"fuzzy":%v,"fuzzy_confidence":%.3f}`, s < 200, 1/(1+s/200))
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	sharp, blurry := blurredImage(200, 150, 0), blurredImage(200, 150, 6)
+	thr := (Sharpness(sharp) + Sharpness(blurry)) / 2
+	y.SetBlurGate(&BlurGateOptions{Threshold: thr, Endpoints: []string{"fuzzydetect"}})
+	var ve *ValidationError
+	if _, err := y.FuzzyDetectImage(ImagePNG(blurry), ""); !errors.As(err, &ve) || ve.Endpoint != "fuzzydetect" || len(bodies) != 0 {
+		t.Errorf("blurry image not gated: %v, %d requests\n", err, len(bodies))
+	}
+	data, _ := ImagePNG(blurry).Bytes()
+	if _, err := y.FuzzyDetectImage(ImageBytes(data), ""); err == nil || len(bodies) != 0 {
+		t.Errorf("blurry image data not gated: %v\n", err)
+	}
+	if _, err := y.FuzzyDetectImage(ImagePNG(sharp), ""); err != nil || len(bodies) != 1 {
+		t.Errorf("sharp image gated: %v\n", err)
+	}
+	if _, err := y.DetectFaceImage(ImagePNG(blurry), false); err != nil {
+		t.Errorf("image gated on an endpoint outside Endpoints: %v\n", err)
+	}
+
+	//校准请求不受拦截
+	bodies = nil
+	var images []Image
+	for _, r := range []int{0, 1, 2, 4, 6, 8} {
+		images = append(images, ImagePNG(blurredImage(200, 150, r)))
+	}
+	images = append(images, ImageBytes([]byte("not an image")))
+	c := y.CalibrateBlur(images)
+	if len(bodies) != 6 || len(c.Samples) != 7 || c.Samples[6].Err == nil {
+		t.Errorf("CalibrateBlur sent %d requests, %d samples\n", len(bodies), len(c.Samples))
+		return
+	}
+	if c.Accuracy != 1 || c.Correlation >= -0.5 || c.SafeThreshold < c.Threshold {
+		t.Errorf("CalibrateBlur = %+v\n", c)
+	}
+	for _, s := range c.Samples[:6] {
+		if (s.Sharpness < c.Threshold) != s.Fuzzy {
+			t.Errorf("threshold %.1f disagrees with fuzzy=%v at sharpness %.1f\n", c.Threshold, s.Fuzzy, s.Sharpness)
+		}
+	}
+}
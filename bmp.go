@@ -0,0 +1,262 @@
+/*
+* File Name:	bmp.go
+* Description:  BMP解码: 调色板、16/24/32位、位域和RLE压缩, 以及OS/2格式
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+var errBMPFormat = errors.New("bmp: invalid format")
+
+//bmpInfo BMP头中解码需要的字段
+type bmpInfo struct {
+	width, height int
+	topDown       bool
+	bpp           int
+	compression   uint32
+	masks         [4]uint32 //R, G, B, A
+	palette       color.Palette
+	offset        int
+}
+
+func parseBMPHeader(data []byte) (info bmpInfo, err error) {
+	if len(data) < 26 || data[0] != 'B' || data[1] != 'M' {
+		return info, errBMPFormat
+	}
+	le := binary.LittleEndian
+	info.offset = int(le.Uint32(data[10:]))
+	hsize := int(le.Uint32(data[14:]))
+	palEntry := 4
+	if hsize == 12 {
+		//OS/2 BITMAPCOREHEADER
+		info.width = int(le.Uint16(data[18:]))
+		info.height = int(int16(le.Uint16(data[20:])))
+		info.bpp = int(le.Uint16(data[24:]))
+		palEntry = 3
+	} else {
+		if hsize < 40 || len(data) < 14+hsize {
+			return info, errBMPFormat
+		}
+		info.width = int(int32(le.Uint32(data[18:])))
+		info.height = int(int32(le.Uint32(data[22:])))
+		info.bpp = int(le.Uint16(data[28:]))
+		info.compression = le.Uint32(data[30:])
+	}
+	if info.height < 0 {
+		info.height, info.topDown = -info.height, true
+	}
+	if info.width <= 0 || info.height <= 0 || info.width > 1<<15 || info.height > 1<<15 {
+		return info, errBMPFormat
+	}
+	//RLE数据可以远小于像素数, 按声明的尺寸分配前限制像素数
+	if info.width*info.height > MaxDecodePixels {
+		return info, errBMPFormat
+	}
+
+	switch info.compression {
+	case 3, 6: //BI_BITFIELDS, BI_ALPHABITFIELDS
+		n := 3
+		if info.compression == 6 || hsize >= 56 {
+			n = 4
+		}
+		if len(data) < 54+n*4 {
+			return info, errBMPFormat
+		}
+		for i := 0; i < n; i++ {
+			info.masks[i] = le.Uint32(data[54+i*4:])
+		}
+	case 0, 1, 2:
+		switch info.bpp {
+		case 16:
+			info.masks = [4]uint32{0x7c00, 0x03e0, 0x001f, 0}
+		case 24, 32:
+			info.masks = [4]uint32{0xff0000, 0x00ff00, 0x0000ff, 0}
+		}
+	default:
+		return info, fmt.Errorf("bmp: unsupported compression %d", info.compression)
+	}
+
+	if info.bpp <= 8 {
+		n := 1 << uint(info.bpp)
+		if hsize >= 40 {
+			if used := int(le.Uint32(data[46:])); used > 0 && used < n {
+				n = used
+			}
+		}
+		start := 14 + hsize
+		if info.compression == 3 && hsize == 40 {
+			start += 12
+		}
+		if start+n*palEntry > len(data) {
+			return info, errBMPFormat
+		}
+		info.palette = make(color.Palette, n)
+		for i := range info.palette {
+			p := data[start+i*palEntry:]
+			info.palette[i] = color.RGBA{p[2], p[1], p[0], 0xff}
+		}
+	}
+	return info, nil
+}
+
+//maskValue 取出v中mask对应的位并扩展为8位
+func maskValue(v, mask uint32) uint8 {
+	if mask == 0 {
+		return 0
+	}
+	shift := bits.TrailingZeros32(mask)
+	n := bits.OnesCount32(mask)
+	x := (v & mask) >> uint(shift)
+	if n >= 8 {
+		return uint8(x >> uint(n-8))
+	}
+	//低位不足8位时按比例放大
+	return uint8(x * 255 / (1<<uint(n) - 1))
+}
+
+//decodeBMP 解码BMP
+func decodeBMP(data []byte) (image.Image, error) {
+	info, err := parseBMPHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if info.offset <= 0 || info.offset > len(data) {
+		return nil, errBMPFormat
+	}
+	pix := data[info.offset:]
+	w, h := info.width, info.height
+	row := func(y int) int {
+		if info.topDown {
+			return y
+		}
+		return h - 1 - y
+	}
+
+	if info.compression == 1 || info.compression == 2 {
+		if info.palette == nil {
+			return nil, errBMPFormat
+		}
+		return decodeBMPRLE(pix, info, row)
+	}
+
+	stride := (w*info.bpp + 31) / 32 * 4
+	if len(pix) < stride*h {
+		return nil, errBMPFormat
+	}
+	if info.bpp <= 8 {
+		switch info.bpp {
+		case 1, 2, 4, 8:
+		default:
+			return nil, fmt.Errorf("bmp: unsupported bit depth %d", info.bpp)
+		}
+		m := image.NewPaletted(image.Rect(0, 0, w, h), info.palette)
+		ppb := 8 / info.bpp
+		for y := 0; y < h; y++ {
+			src := pix[row(y)*stride:]
+			for x := 0; x < w; x++ {
+				b := src[x/ppb]
+				shift := uint(8 - info.bpp*(x%ppb+1))
+				idx := (b >> shift) & (1<<uint(info.bpp) - 1)
+				if int(idx) >= len(info.palette) {
+					idx = 0
+				}
+				m.Pix[y*m.Stride+x] = idx
+			}
+		}
+		return m, nil
+	}
+
+	if info.bpp != 16 && info.bpp != 24 && info.bpp != 32 {
+		return nil, fmt.Errorf("bmp: unsupported bit depth %d", info.bpp)
+	}
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+	bpp := info.bpp / 8
+	alpha := info.masks[3] != 0
+	for y := 0; y < h; y++ {
+		src := pix[row(y)*stride:]
+		for x := 0; x < w; x++ {
+			var v uint32
+			for i := 0; i < bpp; i++ {
+				v |= uint32(src[x*bpp+i]) << uint(8*i)
+			}
+			d := m.Pix[y*m.Stride+x*4:]
+			d[0] = maskValue(v, info.masks[0])
+			d[1] = maskValue(v, info.masks[1])
+			d[2] = maskValue(v, info.masks[2])
+			d[3] = 0xff
+			if alpha {
+				d[3] = maskValue(v, info.masks[3])
+			}
+		}
+	}
+	return m, nil
+}
+
+//decodeBMPRLE 解码RLE8(compression 1)和RLE4(compression 2)
+func decodeBMPRLE(src []byte, info bmpInfo, row func(int) int) (image.Image, error) {
+	w, h := info.width, info.height
+	m := image.NewPaletted(image.Rect(0, 0, w, h), info.palette)
+	rle4 := info.compression == 2
+	//RLE按文件中的行序(自下而上)解码
+	x, fy := 0, 0
+	put := func(idx byte) {
+		if x < w && fy < h && int(idx) < len(info.palette) {
+			m.Pix[row(fy)*m.Stride+x] = idx
+		}
+		x++
+	}
+	for i := 0; i+1 < len(src); {
+		n, c := int(src[i]), src[i+1]
+		i += 2
+		if n > 0 {
+			for k := 0; k < n; k++ {
+				if rle4 {
+					put((c >> uint(4*(1-k%2))) & 0x0f)
+				} else {
+					put(c)
+				}
+			}
+			continue
+		}
+		switch c {
+		case 0: //行结束
+			x, fy = 0, fy+1
+		case 1: //图像结束
+			return m, nil
+		case 2: //偏移
+			if i+1 >= len(src) {
+				return m, nil
+			}
+			x += int(src[i])
+			fy += int(src[i+1])
+			i += 2
+		default: //原样数据
+			cnt := int(c)
+			nbytes := cnt
+			if rle4 {
+				nbytes = (cnt + 1) / 2
+			}
+			if i+nbytes > len(src) {
+				return nil, errBMPFormat
+			}
+			for k := 0; k < cnt; k++ {
+				if rle4 {
+					put((src[i+k/2] >> uint(4*(1-k%2))) & 0x0f)
+				} else {
+					put(src[i+k])
+				}
+			}
+			i += (nbytes + 1) &^ 1 //按2字节对齐
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,422 @@
+/*
+* File Name:	card.go
+* Description:  纯Go的证件边界检测和透视校正: 找出卡片四边形, 拉正为标准宽高比并裁剪
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"errors"
+	"image"
+	"math"
+	"sort"
+)
+
+const (
+	//CardAspectID1 ID-1卡片(身份证、银行卡、驾驶证)的宽高比, 85.6mm x 53.98mm
+	CardAspectID1 = 85.6 / 53.98
+	//DefaultCardMinArea 卡片至少占图片面积的比例
+	DefaultCardMinArea = 0.15
+	//DefaultCardMaxSize 校正后图片长边的默认最大值
+	DefaultCardMaxSize = 1200
+	//cardAnalysisSize 检测时把图片缩小到的长边
+	cardAnalysisSize = 512
+	//cardMinContrast 卡片与背景的最小颜色差(三个通道差的和)
+	cardMinContrast = 40
+	//cardMinFill 四边形占卡片凸包面积的最小比例, 低于该值时形状不像卡片
+	cardMinFill = 0.9
+)
+
+//ErrCardNotFound 没有找到卡片边界
+var ErrCardNotFound = errors.New("card boundary not found")
+
+//CardOptions 卡片校正选项, 值为0的项使用默认值
+type CardOptions struct {
+	Aspect     float64 //卡片长边与短边之比, 为0时使用CardAspectID1
+	KeepAspect bool    //按检测到的四边形边长决定输出宽高, 忽略Aspect
+	MinArea    float64 //卡片至少占图片面积的比例
+	MaxSize    int     //输出图片长边的最大值
+}
+
+//CorrectCard 找出img中卡片的四边形, 透视校正为标准宽高比并裁剪, 返回的图片记录了
+//原图到校正后图片的变换, 识别结果可用Original映射回原图. 卡片竖放时输出竖图.
+//quad为原图中卡片的四个角, 依次为左上、右上、右下、左下.
+//找不到卡片时返回ErrCardNotFound, out为原图, 可以直接用于识别
+func (y *Youtu) CorrectCard(img Image, o CardOptions) (out Image, quad Polygon, err error) {
+	if o.Aspect <= 0 {
+		o.Aspect = CardAspectID1
+	}
+	if o.Aspect < 1 {
+		o.Aspect = 1 / o.Aspect
+	}
+	if o.MinArea <= 0 {
+		o.MinArea = DefaultCardMinArea
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultCardMaxSize
+	}
+	m, t, err := y.decodeImage("", img)
+	if err != nil {
+		return
+	}
+	corners, ok := detectCardQuad(m, o.MinArea)
+	if !ok {
+		return img, nil, ErrCardNotFound
+	}
+
+	w, h := cardSize(corners, o)
+	dst := [4]Point{{0, 0}, {float64(w), 0}, {float64(w), float64(h)}, {0, float64(h)}}
+	hm, ok := homography(corners, dst)
+	if !ok {
+		return img, nil, ErrCardNotFound
+	}
+	out = ImageJPEG(warpPerspective(m, hm, w, h), DefaultNormalizeQuality).WithTransform(t.Then(hm))
+	quad = Polygon(corners[:])
+	if inv, ok := t.Inverse(); ok && !t.IsIdentity() {
+		quad = inv.MapPolygon(quad)
+	}
+	return
+}
+
+//cardSize 校正后图片的宽高, 长边不超过MaxSize
+func cardSize(c [4]Point, o CardOptions) (w, h int) {
+	dist := func(a, b Point) float64 { return math.Hypot(a.X-b.X, a.Y-b.Y) }
+	fw := math.Max(dist(c[0], c[1]), dist(c[3], c[2]))
+	fh := math.Max(dist(c[0], c[3]), dist(c[1], c[2]))
+	if !o.KeepAspect {
+		if fw >= fh {
+			fh = fw / o.Aspect
+		} else {
+			fw = fh / o.Aspect
+		}
+	}
+	if long := math.Max(fw, fh); long > float64(o.MaxSize) {
+		fw, fh = fw*float64(o.MaxSize)/long, fh*float64(o.MaxSize)/long
+	}
+	return maxInt(1, roundInt(fw)), maxInt(1, roundInt(fh))
+}
+
+//detectCardQuad 在m中找出与背景颜色不同的最大区域, 用凸包中面积最大的四边形近似,
+//返回按左上、右上、右下、左下排列的四个角
+func detectCardQuad(m *image.RGBA, minArea float64) (quad [4]Point, ok bool) {
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	scale := 1.0
+	small := m
+	if long := maxInt(w, h); long > cardAnalysisSize {
+		scale = float64(cardAnalysisSize) / float64(long)
+		small = resize(m, maxInt(1, roundInt(float64(w)*scale)), maxInt(1, roundInt(float64(h)*scale)))
+	}
+	sw, sh := small.Bounds().Dx(), small.Bounds().Dy()
+	if sw < 8 || sh < 8 {
+		return
+	}
+
+	mask := foregroundMask(small)
+	comp := largestComponent(mask, sw, sh)
+	if comp == nil {
+		return
+	}
+	hull := convexHull(comp)
+	if len(hull) < 4 {
+		return
+	}
+	q := maxAreaQuad(hull)
+	area := q.Area()
+	if area < minArea*float64(sw*sh) || area < cardMinFill*hull.Area() {
+		return
+	}
+	for i := range q {
+		q[i] = q[i].Scale(1/scale, 1/scale)
+	}
+	return orderCorners(q), true
+}
+
+//foregroundMask 与边框颜色(中值)差别明显的像素, 阈值由Otsu法确定
+func foregroundMask(m *image.RGBA) []bool {
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	var border [3][]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x > 1 && y > 1 && x < w-2 && y < h-2 {
+				continue
+			}
+			p := m.Pix[y*m.Stride+x*4:]
+			for c := 0; c < 3; c++ {
+				border[c] = append(border[c], int(p[c]))
+			}
+		}
+	}
+	var bg [3]int
+	for c := range bg {
+		sort.Ints(border[c])
+		bg[c] = border[c][len(border[c])/2]
+	}
+
+	//颜色差先做3x3均值平滑, 去掉噪点和纹理
+	diff := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := m.Pix[y*m.Stride+x*4:]
+			d := 0
+			for c := 0; c < 3; c++ {
+				if v := int(p[c]) - bg[c]; v < 0 {
+					d -= v
+				} else {
+					d += v
+				}
+			}
+			diff[y*w+x] = d
+		}
+	}
+	smooth := make([]int, w*h)
+	var hist [766]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum, n := 0, 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if xx, yy := x+dx, y+dy; xx >= 0 && yy >= 0 && xx < w && yy < h {
+						sum += diff[yy*w+xx]
+						n++
+					}
+				}
+			}
+			smooth[y*w+x] = sum / n
+			hist[sum/n]++
+		}
+	}
+	thr := maxInt(otsu(hist[:]), cardMinContrast)
+	mask := make([]bool, w*h)
+	for i, d := range smooth {
+		mask[i] = d > thr
+	}
+	return mask
+}
+
+//otsu 使类间方差最大的阈值
+func otsu(hist []int) int {
+	total, sum := 0, 0.0
+	for i, n := range hist {
+		total += n
+		sum += float64(i * n)
+	}
+	var wb, sumB, best float64
+	thr := 0
+	for i, n := range hist {
+		wb += float64(n)
+		if wb == 0 {
+			continue
+		}
+		wf := float64(total) - wb
+		if wf == 0 {
+			break
+		}
+		sumB += float64(i * n)
+		mb, mf := sumB/wb, (sum-sumB)/wf
+		if v := wb * wf * (mb - mf) * (mb - mf); v > best {
+			best, thr = v, i
+		}
+	}
+	return thr
+}
+
+//largestComponent 四连通的最大前景区域的边界像素, 每个像素取四个角点
+func largestComponent(mask []bool, w, h int) []Point {
+	labels := make([]int32, w*h)
+	var best []int
+	stack := make([]int, 0, 1024)
+	label := int32(0)
+	for i, fg := range mask {
+		if !fg || labels[i] != 0 {
+			continue
+		}
+		label++
+		var comp []int
+		stack = append(stack[:0], i)
+		labels[i] = label
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			comp = append(comp, p)
+			x, y := p%w, p/w
+			for _, n := range [4][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n[0] < 0 || n[1] < 0 || n[0] >= w || n[1] >= h {
+					continue
+				}
+				if q := n[1]*w + n[0]; mask[q] && labels[q] == 0 {
+					labels[q] = label
+					stack = append(stack, q)
+				}
+			}
+		}
+		if len(comp) > len(best) {
+			best = comp
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	l := labels[best[0]]
+	inside := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && labels[y*w+x] == l
+	}
+	var pts []Point
+	for _, p := range best {
+		x, y := p%w, p/w
+		if inside(x-1, y) && inside(x+1, y) && inside(x, y-1) && inside(x, y+1) {
+			continue
+		}
+		fx, fy := float64(x), float64(y)
+		pts = append(pts, Point{fx, fy}, Point{fx + 1, fy}, Point{fx, fy + 1}, Point{fx + 1, fy + 1})
+	}
+	return pts
+}
+
+//convexHull 单调链法求凸包
+func convexHull(pts []Point) Polygon {
+	sort.Slice(pts, func(i, j int) bool {
+		if pts[i].X != pts[j].X {
+			return pts[i].X < pts[j].X
+		}
+		return pts[i].Y < pts[j].Y
+	})
+	cross := func(o, a, b Point) float64 {
+		return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+	}
+	hull := make(Polygon, 0, 2*len(pts))
+	for _, p := range pts {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	for i, lower := len(pts)-2, len(hull)+1; i >= 0; i-- {
+		p := pts[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+//maxAreaQuad 凸多边形中以顶点为角的面积最大的四边形
+func maxAreaQuad(p Polygon) Polygon {
+	n := len(p)
+	tri := func(a, b, c Point) float64 {
+		return math.Abs((b.X-a.X)*(c.Y-a.Y)-(b.Y-a.Y)*(c.X-a.X)) / 2
+	}
+	var best float64
+	var quad Polygon
+	for i := 0; i < n; i++ {
+		for k := i + 2; k < n; k++ {
+			//对角线i-k两侧各取面积最大的三角形
+			var a1, a2 float64
+			j1, j2 := -1, -1
+			for j := i + 1; j < k; j++ {
+				if a := tri(p[i], p[j], p[k]); a > a1 {
+					a1, j1 = a, j
+				}
+			}
+			for j := k + 1; j < n+i; j++ {
+				if a := tri(p[k], p[j%n], p[i]); a > a2 {
+					a2, j2 = a, j%n
+				}
+			}
+			if j1 >= 0 && j2 >= 0 && a1+a2 > best {
+				best = a1 + a2
+				quad = Polygon{p[i], p[j1], p[k], p[j2]}
+			}
+		}
+	}
+	return quad
+}
+
+//orderCorners 把四边形的角排成左上、右上、右下、左下
+func orderCorners(q Polygon) (c [4]Point) {
+	var cx, cy float64
+	for _, p := range q {
+		cx, cy = cx+p.X/4, cy+p.Y/4
+	}
+	pts := append(Polygon(nil), q...)
+	//按绕中心的角度排序, 图片坐标y向下, 角度增大为顺时针
+	sort.Slice(pts, func(i, j int) bool {
+		return math.Atan2(pts[i].Y-cy, pts[i].X-cx) < math.Atan2(pts[j].Y-cy, pts[j].X-cx)
+	})
+	first := 0
+	for i, p := range pts {
+		if p.X+p.Y < pts[first].X+pts[first].Y {
+			first = i
+		}
+	}
+	for i := range c {
+		c[i] = pts[(first+i)%4]
+	}
+	return
+}
+
+//homography 把src的四个点映射到dst的透视变换
+func homography(src, dst [4]Point) (Transform, bool) {
+	//未知数h0..h7, h8=1
+	var a [8][9]float64
+	for i := 0; i < 4; i++ {
+		x, y, u, v := src[i].X, src[i].Y, dst[i].X, dst[i].Y
+		a[2*i] = [9]float64{x, y, 1, 0, 0, 0, -u * x, -u * y, u}
+		a[2*i+1] = [9]float64{0, 0, 0, x, y, 1, -v * x, -v * y, v}
+	}
+	//列主元高斯消元
+	for c := 0; c < 8; c++ {
+		p := c
+		for r := c + 1; r < 8; r++ {
+			if math.Abs(a[r][c]) > math.Abs(a[p][c]) {
+				p = r
+			}
+		}
+		if math.Abs(a[p][c]) < 1e-12 {
+			return Transform{}, false
+		}
+		a[c], a[p] = a[p], a[c]
+		for r := 0; r < 8; r++ {
+			if r == c {
+				continue
+			}
+			f := a[r][c] / a[c][c]
+			for k := c; k < 9; k++ {
+				a[r][k] -= f * a[c][k]
+			}
+		}
+	}
+	var m [9]float64
+	for i := 0; i < 8; i++ {
+		m[i] = a[i][8] / a[i][i]
+	}
+	m[8] = 1
+	return NewTransform(m), true
+}
+
+//warpPerspective 按t(src到输出的变换)生成w*h的图片, 双线性插值
+func warpPerspective(src *image.RGBA, t Transform, w, h int) *image.RGBA {
+	inv, _ := t.Inverse()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			//像素中心映射回原图, 再换算到像素中心为整数的坐标
+			sx, sy := inv.Apply(float64(x)+0.5, float64(y)+0.5)
+			sx, sy = sx-0.5, sy-0.5
+			x0, y0 := int(math.Floor(sx)), int(math.Floor(sy))
+			fx, fy := sx-float64(x0), sy-float64(y0)
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for c := 0; c < 4; c++ {
+				at := func(px, py int) float64 {
+					px, py = maxInt(0, minInt(sw-1, px)), maxInt(0, minInt(sh-1, py))
+					return float64(src.Pix[py*src.Stride+px*4+c])
+				}
+				v := (at(x0, y0)*(1-fx)+at(x0+1, y0)*fx)*(1-fy) + (at(x0, y0+1)*(1-fx)+at(x0+1, y0+1)*fx)*fy
+				d[c] = clampUint8(float32(v))
+			}
+		}
+	}
+	return dst
+}
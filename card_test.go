@@ -0,0 +1,121 @@
+/*
+* File Name:	card_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//cardPhoto 生成桌面上斜放卡片的照片: 有纹理的深色背景, 白色卡片上有几行深色文字, 角落有一支笔
+func cardPhoto(quad Polygon) *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	text := []Rect{RectXYWH(120, 110, 120, 12), RectXYWH(110, 140, 150, 12), RectXYWH(100, 170, 90, 12)}
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			p := Pt(float64(x)+0.5, float64(y)+0.5)
+			n := uint8((x*7 + y*13) % 9)
+			c := color.RGBA{60 + n, 70 + n, 80 + n, 255}
+			switch {
+			case quad.Contains(p):
+				c = color.RGBA{240, 238, 230, 255}
+				for _, r := range text {
+					if r.Contains(p) {
+						c = color.RGBA{30, 30, 40, 255}
+					}
+				}
+			case RectXYWH(350, 260, 40, 6).Contains(p):
+				c = color.RGBA{200, 30, 30, 255}
+			}
+			m.SetRGBA(x, y, c)
+		}
+	}
+	return m
+}
+
+func TestDetectCardQuad(t *testing.T) {
+	want := Polygon{{60, 50}, {330, 80}, {310, 250}, {40, 220}}
+	m := cardPhoto(want)
+	got, ok := detectCardQuad(m, DefaultCardMinArea)
+	if !ok {
+		t.Errorf("detectCardQuad found no card\n")
+		return
+	}
+	for i := range want {
+		if d := math.Hypot(got[i].X-want[i].X, got[i].Y-want[i].Y); d > 3 {
+			t.Errorf("corner %d = %v, want %v\n", i, got[i], want[i])
+		}
+	}
+
+	//大图先缩小检测, 角点换算回原图坐标
+	big, ok := detectCardQuad(resize(m, 1200, 900), DefaultCardMinArea)
+	for i := range want {
+		if !ok || math.Hypot(big[i].X-want[i].X*3, big[i].Y-want[i].Y*3) > 8 {
+			t.Errorf("corner %d of 3x image = %v, want %v\n", i, big[i], want[i].Scale(3, 3))
+		}
+	}
+
+	plain := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for i := range plain.Pix {
+		plain.Pix[i] = 0x80
+	}
+	if _, ok := detectCardQuad(plain, DefaultCardMinArea); ok {
+		t.Errorf("detectCardQuad found a card in a plain image\n")
+	}
+}
+
+func TestCorrectCard(t *testing.T) {
+	quad := Polygon{{60, 50}, {330, 80}, {310, 250}, {40, 220}}
+	var sent image.Image
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req BCOcrReq
+		json.NewDecoder(r.Body).Decode(&req)
+		data, _ := base64.StdEncoding.DecodeString(req.Image)
+		sent, _, _ = image.Decode(bytes.NewReader(data))
+		w.Write([]byte(`{"errorcode":0,"items":[{"itemstring":"x","itemcoord":{"x":0,"y":0,"width":10,"height":10}}]}`))
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	img, found, err := y.CorrectCard(ImagePNG(cardPhoto(quad)), CardOptions{})
+	if err != nil || len(found) != 4 {
+		t.Errorf("CorrectCard = %v, %v\n", found, err)
+		return
+	}
+	rsp, err := y.BCOcrImage(img, "")
+	if err != nil || sent == nil {
+		t.Errorf("BCOcrImage with corrected card failed: %v\n", err)
+		return
+	}
+	//长边取四边形较长的上边, 短边按ID-1比例
+	b := sent.Bounds()
+	if b.Dx() < 268 || b.Dx() > 274 || b.Dy() != roundInt(float64(b.Dx())/CardAspectID1) {
+		t.Errorf("corrected size = %v\n", b.Size())
+	}
+	//校正后只剩卡片: 四角附近是卡片的白色
+	for _, p := range []image.Point{{4, 4}, {b.Dx() - 5, 4}, {4, b.Dy() - 5}, {b.Dx() - 5, b.Dy() - 5}} {
+		if r, _, _, _ := sent.At(p.X, p.Y).RGBA(); r>>8 < 200 {
+			t.Errorf("corrected pixel %v = %v, want card white\n", p, sent.At(p.X, p.Y))
+		}
+	}
+	//校正后图片左上角的框映射回原图卡片的左上角
+	if c := rsp.Original().Items[0].Itemcoord; math.Hypot(float64(c.X)-60, float64(c.Y)-50) > 4 {
+		t.Errorf("Original itemcoord = %+v\n", c)
+	}
+
+	plain := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	if _, _, err := y.CorrectCard(ImagePNG(plain), CardOptions{}); err != ErrCardNotFound {
+		t.Errorf("CorrectCard on a plain image: err = %v\n", err)
+	}
+}
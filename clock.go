@@ -0,0 +1,64 @@
+/*
+* File Name:	clock.go
+* Description:  根据服务器Date头估计本地时钟偏差, 签名时修正
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+//now 经时钟偏差修正后的当前时间, 用于签名
+func (y *Youtu) now() time.Time {
+	return time.Now().Add(y.ClockSkew())
+}
+
+//ClockSkew 返回测得的服务器时间与本地时间之差, 正值表示本地时钟偏慢.
+//偏差从响应的Date头测得, 精度约为1秒
+func (y *Youtu) ClockSkew() time.Duration {
+	return time.Duration(atomic.LoadInt64(&y.shared.skew))
+}
+
+//DefaultSignExpiredCodes 服务器表示签名过期的errorcode
+var DefaultSignExpiredCodes = []int{9}
+
+//SetSignExpiredCodes 设置表示签名过期的errorcode, 替换默认的DefaultSignExpiredCodes.
+//收到这些errorcode或HTTP 401时, 用修正后的时间重新签名并重试一次
+func (y *Youtu) SetSignExpiredCodes(codes ...int) {
+	y.shared.mu.Lock()
+	y.shared.signExpiredCodes = append([]int(nil), codes...)
+	y.shared.mu.Unlock()
+}
+
+func (y *Youtu) signExpired(errorCode int, err error) bool {
+	if he, ok := err.(*HTTPError); ok {
+		return he.StatusCode == http.StatusUnauthorized
+	}
+	if err != nil || errorCode == 0 {
+		return false
+	}
+	y.shared.mu.RLock()
+	defer y.shared.mu.RUnlock()
+	for _, c := range y.shared.signExpiredCodes {
+		if c == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+//observeDate 用请求发出和收到响应的本地时间的中点估计偏差
+func (y *Youtu) observeDate(sent, received time.Time, header http.Header) {
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return
+	}
+	local := sent.Add(received.Sub(sent) / 2)
+	//Date只精确到秒, 取本地时间的整秒比较, 避免引入半秒的系统误差
+	skew := date.Sub(local.Truncate(time.Second))
+	atomic.StoreInt64(&y.shared.skew, int64(skew))
+}
@@ -0,0 +1,92 @@
+/*
+* File Name:	clock_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//signTime 从Authorization头中取出签名时间t
+func signTime(auth string) (time.Time, error) {
+	raw, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return time.Time{}, err
+	}
+	//前20字节为HMAC-SHA1, 之后为原始签名串
+	v, err := url.ParseQuery(string(raw[20:]))
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := strconv.ParseInt(v.Get("t"), 10, 64)
+	return time.Unix(t, 0), err
+}
+
+func TestClockSkew(t *testing.T) {
+	const skew = time.Hour
+	requests, expiredCode := 0, -42
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		serverNow := time.Now().Add(skew)
+		w.Header().Set("Date", serverNow.UTC().Format(http.TimeFormat))
+		st, err := signTime(r.Header.Get("Authorization"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if d := serverNow.Sub(st); d > time.Minute || d < -time.Minute {
+			json.NewEncoder(w).Encode(GetGroupIDsRsp{ErrorCode: int32(expiredCode), ErrorMsg: "sign expired"})
+			return
+		}
+		json.NewEncoder(w).Encode(GetGroupIDsRsp{GroupIDs: []string{"ok"}})
+	}))
+	defer srv.Close()
+
+	as, _ := NewAppSign(1, "id", "key", "user")
+	y := Init(as, srv.URL)
+
+	rsp, err := y.GetGroupIDs()
+	if err != nil {
+		t.Errorf("GetGroupIDs failed: %s\n", err)
+		return
+	}
+	if rsp.ErrorCode != -42 {
+		t.Errorf("request without configured sign expired code should not be retried: %#v\n", rsp)
+	}
+	if d := y.ClockSkew() - skew; d > 2*time.Second || d < -2*time.Second {
+		t.Errorf("ClockSkew = %v, want about %v\n", y.ClockSkew(), skew)
+	}
+
+	//新客户端首次签名过期, 按Date头修正偏差后重新签名成功
+	requests = 0
+	y2 := Init(as, srv.URL)
+	y2.SetSignExpiredCodes(-42)
+	rsp, err = y2.GetGroupIDs()
+	if err != nil || rsp.ErrorCode != 0 {
+		t.Errorf("GetGroupIDs = %#v, %v, want success after re-sign\n", rsp, err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 (expired and retried)\n", requests)
+	}
+
+	//默认识别服务器的签名过期errorcode
+	requests, expiredCode = 0, DefaultSignExpiredCodes[0]
+	y3 := Init(as, srv.URL)
+	rsp, err = y3.GetGroupIDs()
+	if err != nil || rsp.ErrorCode != 0 {
+		t.Errorf("GetGroupIDs = %#v, %v, want success with default sign expired codes\n", rsp, err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 (expired and retried)\n", requests)
+	}
+}
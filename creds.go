@@ -0,0 +1,220 @@
+/*
+* File Name:	creds.go
+* Description:  加密凭据文件, 口令经scrypt派生密钥后用AES-GCM加密AppSign
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	credentialsVersion = 1
+	credentialsKDF     = "scrypt"
+	credentialsSaltLen = 16
+	credentialsKeyLen  = 32
+	credentialsMaxN    = 1 << 20
+	credentialsMaxR    = 32
+	credentialsMaxP    = 16
+	//credentialsMaxMemory 派生密钥占用内存(128*N*r字节)的上限
+	credentialsMaxMemory = 256 << 20
+	//credentialsMaxWork 派生密钥计算量N*r*p的上限, 为默认参数的16倍
+	credentialsMaxWork = 1 << 22
+)
+
+//scrypt参数, N=2^15, r=8, p=1 约占用32MB内存
+var (
+	credentialsN = 1 << 15
+	credentialsR = 8
+	credentialsP = 1
+)
+
+var (
+	//ErrBadPassphrase 口令错误或凭据文件被篡改
+	ErrBadPassphrase = errors.New("credentials: wrong passphrase or corrupted data")
+	//ErrCredentialsFormat 凭据文件格式错误
+	ErrCredentialsFormat = errors.New("credentials: unsupported format")
+	//ErrCredentialsParams 凭据文件的scrypt参数超出上限, 不派生密钥
+	ErrCredentialsParams = errors.New("credentials: scrypt parameters exceed limits")
+)
+
+//credentialsFile 凭据文件的磁盘格式, []byte字段以base64编码
+type credentialsFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"` //AES-256-GCM密文
+}
+
+//paramsOK scrypt参数各自以及组合的内存和计算量都在上限内
+func (f *credentialsFile) paramsOK() bool {
+	if f.N <= 0 || f.R <= 0 || f.P <= 0 || f.N > credentialsMaxN || f.R > credentialsMaxR || f.P > credentialsMaxP {
+		return false
+	}
+	n, r, p := int64(f.N), int64(f.R), int64(f.P)
+	return 128*n*r <= credentialsMaxMemory && n*r*p <= credentialsMaxWork
+}
+
+//additionalData 将文件头作为GCM的附加数据, 防止参数被篡改
+func (f *credentialsFile) additionalData() []byte {
+	return []byte(fmt.Sprintf("youtu-credentials:v=%d:kdf=%s:n=%d:r=%d:p=%d",
+		f.Version, f.KDF, f.N, f.R, f.P))
+}
+
+func (f *credentialsFile) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scryptKey(passphrase, f.Salt, f.N, f.R, f.P, credentialsKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//credentialsPlain 凭据明文
+type credentialsPlain struct {
+	AppID     uint32 `json:"app_id"`
+	SecretID  string `json:"secret_id"`
+	SecretKey string `json:"secret_key"`
+	UserID    string `json:"user_id"`
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+//EncryptCredentials 用口令加密AppSign, 返回凭据文件内容
+func EncryptCredentials(as AppSign, passphrase []byte) ([]byte, error) {
+	f := credentialsFile{
+		Version: credentialsVersion,
+		KDF:     credentialsKDF,
+		N:       credentialsN,
+		R:       credentialsR,
+		P:       credentialsP,
+		Salt:    make([]byte, credentialsSaltLen),
+	}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(credentialsPlain{
+		AppID:     as.appID,
+		SecretID:  as.secretID,
+		SecretKey: string(as.secretKey),
+		UserID:    as.userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(plain)
+	f.Data = aead.Seal(nil, f.Nonce, plain, f.additionalData())
+	return json.MarshalIndent(f, "", "  ")
+}
+
+//DecryptCredentials 用口令解密EncryptCredentials生成的内容
+func DecryptCredentials(data []byte, passphrase []byte) (as AppSign, err error) {
+	var f credentialsFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		err = ErrCredentialsFormat
+		return
+	}
+	if f.Version != credentialsVersion || f.KDF != credentialsKDF {
+		err = ErrCredentialsFormat
+		return
+	}
+	if !f.paramsOK() {
+		err = ErrCredentialsParams
+		return
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		err = ErrCredentialsFormat
+		return
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		err = ErrCredentialsFormat
+		return
+	}
+	plain, err := aead.Open(nil, f.Nonce, f.Data, f.additionalData())
+	if err != nil {
+		err = ErrBadPassphrase
+		return
+	}
+	defer wipe(plain)
+	var p credentialsPlain
+	if err = json.Unmarshal(plain, &p); err != nil {
+		err = ErrCredentialsFormat
+		return
+	}
+	return NewAppSign(p.AppID, p.SecretID, p.SecretKey, p.UserID)
+}
+
+//SaveCredentials 创建加密凭据文件, 文件权限为0600, 已存在的文件会被原子替换
+func SaveCredentials(path string, as AppSign, passphrase []byte) error {
+	data, err := EncryptCredentials(as, passphrase)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".youtu-cred-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err = tmp.Chmod(0600); err == nil {
+		_, err = tmp.Write(data)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+//LoadCredentials 读取加密凭据文件并解密为AppSign
+func LoadCredentials(path string, passphrase []byte) (as AppSign, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	return DecryptCredentials(data, passphrase)
+}
+
+//RotateCredentials 更换凭据文件的口令, 同时重新生成salt和nonce
+func RotateCredentials(path string, oldPassphrase, newPassphrase []byte) error {
+	as, err := LoadCredentials(path, oldPassphrase)
+	if err != nil {
+		return err
+	}
+	return SaveCredentials(path, as, newPassphrase)
+}
@@ -0,0 +1,131 @@
+/*
+* File Name:	creds_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func equalAppSign(a, b AppSign) bool {
+	return a.appID == b.appID && a.secretID == b.secretID &&
+		bytes.Equal(a.secretKey, b.secretKey) && a.userID == b.userID
+}
+
+func TestScryptKey(t *testing.T) {
+	//RFC 7914 测试向量
+	want := "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442" +
+		"fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"
+	key, err := scryptKey([]byte(""), []byte(""), 16, 1, 1, 64)
+	if err != nil {
+		t.Errorf("scryptKey failed: %s\n", err)
+		return
+	}
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("scryptKey = %s, want %s\n", got, want)
+	}
+	if _, err := scryptKey([]byte("p"), []byte("s"), 15, 1, 1, 32); err == nil {
+		t.Errorf("scryptKey accepted N that is not a power of 2\n")
+	}
+}
+
+func TestCredentialsFile(t *testing.T) {
+	credentialsN = 1 << 10
+	defer func() { credentialsN = 1 << 15 }()
+
+	dir, err := ioutil.TempDir("", "youtu-cred")
+	if err != nil {
+		t.Errorf("TempDir failed: %s\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "youtu.cred")
+
+	as, _ := NewAppSign(1000061, "secret-id", "secret-key", "10000")
+	if err := SaveCredentials(path, as, []byte("old pass")); err != nil {
+		t.Errorf("SaveCredentials failed: %s\n", err)
+		return
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Errorf("Stat failed: %s\n", err)
+		return
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("credentials file mode = %v, want 0600\n", fi.Mode().Perm())
+	}
+	data, _ := ioutil.ReadFile(path)
+	if bytes.Contains(data, []byte("secret-key")) {
+		t.Errorf("credentials file contains plaintext secret\n")
+	}
+
+	got, err := LoadCredentials(path, []byte("old pass"))
+	if err != nil {
+		t.Errorf("LoadCredentials failed: %s\n", err)
+		return
+	}
+	if !equalAppSign(got, as) {
+		t.Errorf("LoadCredentials = %v, want %v\n", got, as)
+	}
+	if _, err := LoadCredentials(path, []byte("wrong pass")); err != ErrBadPassphrase {
+		t.Errorf("LoadCredentials with wrong passphrase: err = %v, want %v\n", err, ErrBadPassphrase)
+	}
+
+	if err := RotateCredentials(path, []byte("old pass"), []byte("new pass")); err != nil {
+		t.Errorf("RotateCredentials failed: %s\n", err)
+		return
+	}
+	if _, err := LoadCredentials(path, []byte("old pass")); err != ErrBadPassphrase {
+		t.Errorf("old passphrase still works after rotation: err = %v\n", err)
+	}
+	if got, err := LoadCredentials(path, []byte("new pass")); err != nil || !equalAppSign(got, as) {
+		t.Errorf("LoadCredentials after rotation = %v, %v\n", got, err)
+	}
+}
+
+func TestDecryptCredentialsTampered(t *testing.T) {
+	credentialsN = 1 << 10
+	defer func() { credentialsN = 1 << 15 }()
+
+	as, _ := NewAppSign(1, "id", "key", "user")
+	data, err := EncryptCredentials(as, []byte("pass"))
+	if err != nil {
+		t.Errorf("EncryptCredentials failed: %s\n", err)
+		return
+	}
+	tampered := bytes.Replace(data, []byte(`"n": 1024`), []byte(`"n": 2048`), 1)
+	if _, err := DecryptCredentials(tampered, []byte("pass")); err != ErrBadPassphrase {
+		t.Errorf("DecryptCredentials with tampered header: err = %v, want %v\n", err, ErrBadPassphrase)
+	}
+	//参数各自或组合过大时在派生密钥前拒绝, N=2^20, r=32需要4GiB内存
+	for _, hostile := range [][2]string{
+		{`"r": 8`, `"r": 1024`},
+		{`"p": 1`, `"p": 1024`},
+		{`"n": 1024`, `"n": 1048576`},
+		{`"p": 1`, `"p": -1`},
+	} {
+		h := bytes.Replace(data, []byte(hostile[0]), []byte(hostile[1]), 1)
+		if hostile[0] == `"n": 1024` {
+			h = bytes.Replace(h, []byte(`"r": 8`), []byte(`"r": 32`), 1)
+		}
+		start := time.Now()
+		if _, err := DecryptCredentials(h, []byte("pass")); err != ErrCredentialsParams {
+			t.Errorf("DecryptCredentials with %s: err = %v, want %v\n", hostile[1], err, ErrCredentialsParams)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("DecryptCredentials with %s took %v, key must not be derived\n", hostile[1], d)
+		}
+	}
+	if _, err := DecryptCredentials([]byte("app_id=1"), []byte("pass")); err != ErrCredentialsFormat {
+		t.Errorf("DecryptCredentials with garbage: err = %v, want %v\n", err, ErrCredentialsFormat)
+	}
+}
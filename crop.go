@@ -0,0 +1,185 @@
+/*
+* File Name:	crop.go
+* Description:  按检测结果裁剪人脸缩略图, 以及按双眼位置旋转缩放的对齐人脸
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	//DefaultChipSize 对齐人脸的默认边长
+	DefaultChipSize = 112
+	//DefaultChipEyeDistance 对齐人脸中两眼中心距离占边长的默认比例
+	DefaultChipEyeDistance = 0.35
+	//DefaultChipEyeY 对齐人脸中两眼连线的高度占边长的默认比例
+	DefaultChipEyeY = 0.4
+)
+
+//CropOptions 人脸裁剪选项
+type CropOptions struct {
+	Margin float64 //人脸框四周向外扩展的比例(相对框的宽高), 如0.2
+	Square bool    //扩展为以人脸框中心为中心的正方形
+	Size   int     //输出图片的长边, 为0时不缩放
+}
+
+//FaceCrop 裁剪出的一张人脸
+type FaceCrop struct {
+	Index int         //在结果中的序号
+	Rect  Rect        //裁剪区域, 为按EXIF方向摆正后的图片坐标, 已截取到图片范围内
+	Image *image.RGBA //裁剪出的图片
+}
+
+//faceSource 解码img, 返回结果坐标到解码图片坐标的变换. rspT为请求时记录的原图到发送图片的变换
+func (y *Youtu) faceSource(img Image, rspT *Transform) (m *image.RGBA, toImage Transform, err error) {
+	m, t, err := y.decodeImage("", img)
+	if err != nil {
+		return
+	}
+	toImage = resultTransform(t, rspT)
+	return
+}
+
+//resultTransform 结果坐标到解码图片坐标的变换, t为原图到解码图片的变换,
+//rspT为请求时记录的原图到发送图片的变换, 为nil时结果坐标按原图处理
+func resultTransform(t Transform, rspT *Transform) Transform {
+	if rspT != nil {
+		if inv, ok := rspT.Inverse(); ok {
+			return inv.Then(t)
+		}
+	}
+	return t
+}
+
+//cropRects 按选项扩展并裁剪每个框, rects为解码图片坐标
+func cropRects(m *image.RGBA, rects []Rect, o CropOptions) []FaceCrop {
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	var crops []FaceCrop
+	for i, r := range rects {
+		dx, dy := r.Dx()*o.Margin, r.Dy()*o.Margin
+		r = Rect{Pt(r.Min.X-dx, r.Min.Y-dy), Pt(r.Max.X+dx, r.Max.Y+dy)}
+		if o.Square {
+			c, s := r.Center(), math.Max(r.Dx(), r.Dy())/2
+			r = Rect{Pt(c.X-s, c.Y-s), Pt(c.X+s, c.Y+s)}
+		}
+		ir := r.Clip(w, h).ImageRect()
+		if ir.Empty() {
+			continue
+		}
+		out := toRGBA(m.SubImage(ir.Add(m.Bounds().Min)))
+		if o.Size > 0 {
+			sc := float64(o.Size) / float64(maxInt(ir.Dx(), ir.Dy()))
+			out = resize(out, maxInt(1, roundInt(float64(ir.Dx())*sc)), maxInt(1, roundInt(float64(ir.Dy())*sc)))
+		}
+		crops = append(crops, FaceCrop{Index: i, Rect: RectFrom(ir), Image: out})
+	}
+	return crops
+}
+
+//CropFaces 从img中裁剪rsp的每张人脸, rsp应是img的检测结果(记录的Transform会被考虑).
+//完全在图片外的人脸被跳过, 用FaceCrop.Index对应rsp.Face
+func (y *Youtu) CropFaces(img Image, rsp DetectFaceRsp, o CropOptions) (crops []FaceCrop, err error) {
+	m, t, err := y.faceSource(img, rsp.Transform)
+	if err != nil {
+		return
+	}
+	rects := make([]Rect, len(rsp.Face))
+	for i, f := range rsp.Face {
+		rects[i] = t.MapRect(f.Rect())
+	}
+	crops = cropRects(m, rects, o)
+	return
+}
+
+//CropFaceShapes 同CropFaces, 人脸区域取五官定位点的外接矩形
+func (y *Youtu) CropFaceShapes(img Image, rsp FaceShapeRsp, o CropOptions) (crops []FaceCrop, err error) {
+	m, t, err := y.faceSource(img, rsp.Transform)
+	if err != nil {
+		return
+	}
+	rects := make([]Rect, len(rsp.FaceShape))
+	for i, s := range rsp.FaceShape {
+		rects[i] = t.MapPolygon(s.Points()).Bounds()
+	}
+	crops = cropRects(m, rects, o)
+	return
+}
+
+//ChipOptions 对齐人脸选项, 值为0的项使用默认值
+type ChipOptions struct {
+	Size        int     //输出正方形的边长, 默认DefaultChipSize
+	EyeDistance float64 //两眼中心距离占边长的比例, 默认DefaultChipEyeDistance
+	EyeY        float64 //两眼连线的高度占边长的比例, 默认DefaultChipEyeY
+}
+
+//FaceChip 一张对齐的人脸
+type FaceChip struct {
+	Index     int         //在FaceShape中的序号
+	LeftEye   Point       //被拍者左眼的中心, 为按EXIF方向摆正后的图片坐标
+	RightEye  Point       //被拍者右眼的中心
+	Angle     float64     //右眼指向左眼的方向与水平向右的夹角(度), 范围(-180, 180], 对齐时按此旋转
+	Transform Transform   //摆正后的图片到对齐人脸的变换
+	Image     *image.RGBA //对齐后的人脸
+}
+
+//centroid 点的平均位置
+func centroid(pts Polygon) Point {
+	var c Point
+	for _, p := range pts {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	n := float64(len(pts))
+	return Pt(c.X/n, c.Y/n)
+}
+
+//AlignFaces 按FaceShape的双眼位置旋转缩放每张人脸: 右眼在左、左眼在右且两眼水平, 中心距离固定,
+//两眼中点位于水平居中、EyeY高度处, 倒置的人脸也被转正. 缺少眼睛定位点的人脸被跳过
+func (y *Youtu) AlignFaces(img Image, rsp FaceShapeRsp, o ChipOptions) (chips []FaceChip, err error) {
+	if o.Size <= 0 {
+		o.Size = DefaultChipSize
+	}
+	if o.EyeDistance <= 0 {
+		o.EyeDistance = DefaultChipEyeDistance
+	}
+	if o.EyeY <= 0 {
+		o.EyeY = DefaultChipEyeY
+	}
+	m, t, err := y.faceSource(img, rsp.Transform)
+	if err != nil {
+		return
+	}
+	size := float64(o.Size)
+	for i, s := range rsp.FaceShape {
+		if len(s.LeftEye) == 0 || len(s.RightEye) == 0 {
+			continue
+		}
+		left := centroid(t.MapPolygon(posPolygon(s.LeftEye)))
+		right := centroid(t.MapPolygon(posPolygon(s.RightEye)))
+		dx, dy := left.X-right.X, left.Y-right.Y
+		d := math.Hypot(dx, dy)
+		if d == 0 {
+			continue
+		}
+		//以两眼中点为中心旋转-angle并缩放, 再平移到目标位置
+		angle := math.Atan2(dy, dx)
+		sc := o.EyeDistance * size / d
+		a, b := sc*math.Cos(angle), sc*math.Sin(angle)
+		mx, my := (left.X+right.X)/2, (left.Y+right.Y)/2
+		tx, ty := size/2-(a*mx+b*my), o.EyeY*size-(-b*mx+a*my)
+		ct := NewTransform([9]float64{a, b, tx, -b, a, ty, 0, 0, 1})
+		chips = append(chips, FaceChip{
+			Index:     i,
+			LeftEye:   left,
+			RightEye:  right,
+			Angle:     angle * 180 / math.Pi,
+			Transform: ct,
+			Image:     warpPerspective(m, ct, o.Size, o.Size),
+		})
+	}
+	return
+}
@@ -0,0 +1,130 @@
+/*
+* File Name:	crop_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+//eyesImage 白底上以(cx, cy)为中心、间距d、倾斜deg度的两个黑色圆点
+func eyesImage(w, h int, cx, cy, d, deg float64) (*image.RGBA, [2]Point) {
+	a := deg * math.Pi / 180
+	ex, ey := d/2*math.Cos(a), d/2*math.Sin(a)
+	eyes := [2]Point{Pt(cx-ex, cy-ey), Pt(cx+ex, cy+ey)}
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{255, 255, 255, 255}
+			for _, e := range eyes {
+				if math.Hypot(float64(x)+0.5-e.X, float64(y)+0.5-e.Y) < 4 {
+					c = color.RGBA{0, 0, 0, 255}
+				}
+			}
+			m.SetRGBA(x, y, c)
+		}
+	}
+	return m, eyes
+}
+
+//eyePos 以p为中心的一圈眼睛定位点
+func eyePos(p Point) []pos {
+	var ps []pos
+	for i := 0; i < 8; i++ {
+		a := float64(i) * math.Pi / 4
+		ps = append(ps, pos{int(math.Round(p.X + 3*math.Cos(a))), int(math.Round(p.Y + 3*math.Sin(a)))})
+	}
+	return ps
+}
+
+func TestCropFaces(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for i := range m.Pix {
+		m.Pix[i] = 255
+	}
+	for y := 20; y < 60; y++ {
+		for x := 100; x < 140; x++ {
+			m.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	y := Init(AppSign{}, "")
+	//发送图片缩小为一半时的检测结果
+	half := ScaleTransform(0.5, 0.5)
+	rsp := DetectFaceRsp{ImageWidth: 100, ImageHeight: 50, Transform: &half, Face: []Face{
+		{X: 50, Y: 10, Width: 20, Height: 20},
+		{X: 90, Y: 40, Width: 20, Height: 20},
+		{X: 300, Y: 300, Width: 10, Height: 10},
+	}}
+	crops, err := y.CropFaces(ImagePNG(m), rsp, CropOptions{})
+	if err != nil || len(crops) != 2 {
+		t.Errorf("CropFaces = %d crops, %v\n", len(crops), err)
+		return
+	}
+	if b := crops[0].Image.Bounds(); b != image.Rect(0, 0, 40, 40) || crops[0].Image.RGBAAt(20, 20) != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("crop 0 = %v, center %v\n", b, crops[0].Image.RGBAAt(20, 20))
+	}
+	//超出图片的部分被截掉
+	if crops[1].Index != 1 || crops[1].Rect != RectXYWH(180, 80, 20, 20) {
+		t.Errorf("crop 1 = %d %v\n", crops[1].Index, crops[1].Rect)
+	}
+
+	crops, _ = y.CropFaces(ImagePNG(m), rsp, CropOptions{Margin: 0.25, Square: true, Size: 30})
+	if crops[0].Rect != RectXYWH(90, 10, 60, 60) || crops[0].Image.Bounds().Dx() != 30 {
+		t.Errorf("crop with margin = %v, %v\n", crops[0].Rect, crops[0].Image.Bounds())
+	}
+}
+
+func TestAlignFaces(t *testing.T) {
+	m, eyes := eyesImage(200, 160, 90, 70, 40, 20)
+	rsp := FaceShapeRsp{ImageWidth: 200, ImageHeight: 160, FaceShape: []FaceShape{
+		{LeftEye: eyePos(eyes[1]), RightEye: eyePos(eyes[0])},
+		{},
+	}}
+	y := Init(AppSign{}, "")
+	chips, err := y.AlignFaces(ImagePNG(m), rsp, ChipOptions{Size: 100, EyeDistance: 0.4})
+	if err != nil || len(chips) != 1 {
+		t.Errorf("AlignFaces = %d chips, %v\n", len(chips), err)
+		return
+	}
+	//正脸的右眼在图片左侧
+	c := chips[0]
+	if math.Abs(c.Angle-20) > 2 || math.Hypot(c.LeftEye.X-eyes[1].X, c.LeftEye.Y-eyes[1].Y) > 1 {
+		t.Errorf("chip angle %.1f, left eye %v, want 20, %v\n", c.Angle, c.LeftEye, eyes[1])
+	}
+	//两眼在(30, 40)和(70, 40), 两眼之间是白色
+	img := c.Image
+	for _, p := range []image.Point{{30, 40}, {70, 40}} {
+		if r, _, _, _ := img.At(p.X, p.Y).RGBA(); r>>8 > 100 {
+			t.Errorf("chip pixel %v = %v, want eye black\n", p, img.At(p.X, p.Y))
+		}
+	}
+	for _, p := range []image.Point{{50, 40}, {30, 30}, {70, 50}} {
+		if r, _, _, _ := img.At(p.X, p.Y).RGBA(); r>>8 < 200 {
+			t.Errorf("chip pixel %v = %v, want white\n", p, img.At(p.X, p.Y))
+		}
+	}
+	if x, y := c.Transform.Apply(eyes[1].X, eyes[1].Y); math.Abs(x-70) > 1 || math.Abs(y-40) > 1 {
+		t.Errorf("Transform maps left eye to (%.1f, %.1f)\n", x, y)
+	}
+
+	//倒置的人脸右眼在图片右侧, 对齐后同样是右眼在左
+	rsp.FaceShape = []FaceShape{{LeftEye: eyePos(eyes[0]), RightEye: eyePos(eyes[1])}}
+	chips, err = y.AlignFaces(ImagePNG(m), rsp, ChipOptions{Size: 100, EyeDistance: 0.4})
+	if err != nil || len(chips) != 1 {
+		t.Errorf("AlignFaces upside down = %d chips, %v\n", len(chips), err)
+		return
+	}
+	c = chips[0]
+	if math.Abs(c.Angle+160) > 2 {
+		t.Errorf("upside down chip angle %.1f, want -160\n", c.Angle)
+	}
+	if x, y := c.Transform.Apply(eyes[1].X, eyes[1].Y); math.Abs(x-30) > 1 || math.Abs(y-40) > 1 {
+		t.Errorf("Transform maps upside down right eye to (%.1f, %.1f)\n", x, y)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+* File Name:	deprecated.go
+* Description:  以[]byte和imageType传递图片的旧接口
+* Created:	2026-10-18
+ */
+
+package youtu
+
+//DetectFace 检测给定图片(Image)中的所有人脸(Face)的位置和相应的面部属性。
+//位置包括(x, y, w, h)，面部属性包括性别(gender), 年龄(age), 魅力值(beauty)
+//表情(expression), 眼镜(glass)和姿态(pitch，roll，yaw).
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用DetectFaceImage
+func (y *Youtu) DetectFace(image []byte, isBigFace bool, imageType int) (rsp DetectFaceRsp, err error) {
+	return y.DetectFaceImage(legacyImage(image, imageType), isBigFace)
+}
+
+//FaceShape 对请求图片进行五官定位，计算构成人脸轮廓的88个点，包括眉毛（左右各8点）、眼睛（左右各8点）、鼻子（13点）、嘴巴（22点）、脸型轮廓（21点）
+//imageType 表示image的类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用FaceShapeImage
+func (y *Youtu) FaceShape(image []byte, isBigFace bool, imageType int) (rsp FaceShapeRsp, err error) {
+	return y.FaceShapeImage(legacyImage(image, imageType), isBigFace)
+}
+
+//FaceCompare 计算两个Face的相似性以及五官相似度
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用FaceCompareImage
+func (y *Youtu) FaceCompare(imageA, imageB []byte, imageType int) (rsp FaceCompareRsp, err error) {
+	return y.FaceCompareImage(legacyImage(imageA, imageType), legacyImage(imageB, imageType))
+}
+
+//FaceVerify 给定一个Face和一个Person，返回是否是同一个人的判断以及信度。
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用FaceVerifyImage
+func (y *Youtu) FaceVerify(personID string, image []byte, imageType int) (rsp FaceVerifyRsp, err error) {
+	return y.FaceVerifyImage(personID, legacyImage(image, imageType))
+}
+
+//FaceIdentify 对于一个待识别的人脸图片，在一个Group中识别出最相似的Person作为其身份返回
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用FaceIdentifyImage
+func (y *Youtu) FaceIdentify(groupID string, image []byte, imageType int) (rsp FaceIdentifyRsp, err error) {
+	return y.FaceIdentifyImage(groupID, legacyImage(image, imageType))
+}
+
+//MultiFaceIdentify 上传人脸图片，进行多人脸检索。
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//与以前的版本一样, groupID非空时忽略GroupIds
+//
+//Deprecated: 使用MultiFaceIdentifyImage
+func (y *Youtu) MultiFaceIdentify(groupID string, GroupIds []string, image []byte, imageType int, topn int, minSize int) (rsp MultiFaceIdentifyRsp, err error) {
+	if groupID != "" {
+		GroupIds = nil
+	}
+	return y.MultiFaceIdentifyImage(groupID, GroupIds, legacyImage(image, imageType), topn, minSize)
+}
+
+//NewPerson 创建一个Person，并将Person放置到group_ids指定的组当中
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用NewPersonImage
+func (y *Youtu) NewPerson(personID string, personName string, groupIDs []string, image []byte, tag string, imageType int) (rsp NewPersonRsp, err error) {
+	return y.NewPersonImage(personID, personName, groupIDs, legacyImage(image, imageType), tag)
+}
+
+//AddFace 将一组Face加入到一个Person中。注意，一个Face只能被加入到一个Person中。
+//一个Person最多允许包含10000个Face
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用AddFaceImages
+func (y *Youtu) AddFace(personID string, images [][]byte, tag string, imageType int) (rsp AddFaceRsp, err error) {
+	return y.AddFaceImages(personID, legacyImages(images, imageType), tag)
+}
+
+//FuzzyDetect 检测图片的模糊度
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用FuzzyDetectImage
+func (y *Youtu) FuzzyDetect(image []byte, imageType int, seq string) (rsp FuzzyDetectRsp, err error) {
+	return y.FuzzyDetectImage(legacyImage(image, imageType), seq)
+}
+
+//FoodDetect 美食检测
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用FoodDetectImage
+func (y *Youtu) FoodDetect(image []byte, imageType int, seq string) (rsp FoodDetectRsp, err error) {
+	return y.FoodDetectImage(legacyImage(image, imageType), seq)
+}
+
+//ImageTag 图片分类
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用ImageTagImage
+func (y *Youtu) ImageTag(image []byte, imageType int, seq string) (rsp ImageTagRsp, err error) {
+	return y.ImageTagImage(legacyImage(image, imageType), seq)
+}
+
+//ImagePorn 图片鉴黄
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用ImagePornImage
+func (y *Youtu) ImagePorn(image []byte, imageType int, seq string) (rsp ImagePornRsp, err error) {
+	return y.ImagePornImage(legacyImage(image, imageType), seq)
+}
+
+//ImageTerrorism 图片暴恐检测
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用ImageTerrorismImage
+func (y *Youtu) ImageTerrorism(image []byte, imageType int, seq string) (rsp ImagePornRsp, err error) {
+	return y.ImageTerrorismImage(legacyImage(image, imageType), seq)
+}
+
+//CarClassify 车辆属性识别
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用CarClassifyImage
+func (y *Youtu) CarClassify(image []byte, imageType int, session_id string) (rsp CarClassifyRsp, err error) {
+	return y.CarClassifyImage(legacyImage(image, imageType), session_id)
+}
+
+//IdcardOcr 图片分类
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//cardType 代表身份证正面还是反面，其中0代表正面，1代表反面
+//
+//Deprecated: 使用IdcardOcrImage
+func (y *Youtu) IdcardOcr(image []byte, imageType int, cardType int32, seq string) (rsp IdcardOcrRsp, err error) {
+	return y.IdcardOcrImage(legacyImage(image, imageType), cardType, seq)
+}
+
+//DriverLicenseOcr 行驶证&驾驶证识别
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//procType 表示图片识别类型，其中0代表行驶证，1代表驾驶证
+//
+//Deprecated: 使用DriverLicenseOcrImage
+func (y *Youtu) DriverLicenseOcr(image []byte, imageType int, procType int32, seq string) (rsp DriverlicenseOcrRsp, err error) {
+	return y.DriverLicenseOcrImage(legacyImage(image, imageType), procType, seq)
+}
+
+//BCOcr 名片OCR识别
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用BCOcrImage
+func (y *Youtu) BCOcr(image []byte, imageType int, seq string) (rsp BCOcrRsp, err error) {
+	return y.BCOcrImage(legacyImage(image, imageType), seq)
+}
+
+//GeneralOcr 通用OCR识别
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用GeneralOcrImage
+func (y *Youtu) GeneralOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.GeneralOcrImage(legacyImage(image, imageType), seq)
+}
+
+//CreditCardOcr 银行卡OCR识别
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用CreditCardOcrImage
+func (y *Youtu) CreditCardOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.CreditCardOcrImage(legacyImage(image, imageType), seq)
+}
+
+//BizLicenseOcr 营业执照OCR识别
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用BizLicenseOcrImage
+func (y *Youtu) BizLicenseOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.BizLicenseOcrImage(legacyImage(image, imageType), seq)
+}
+
+//PlateOcr 车牌OCR识别
+//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//
+//Deprecated: 使用PlateOcrImage
+func (y *Youtu) PlateOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.PlateOcrImage(legacyImage(image, imageType), seq)
+}
@@ -0,0 +1,222 @@
+/*
+* File Name:	document.go
+* Description:  多页文档OCR: 拆分多页TIFF和多帧GIF, 并发识别各页并合并文本
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"strings"
+	"sync"
+)
+
+//DefaultDocumentConcurrency 文档OCR同时识别的默认页数
+const DefaultDocumentConcurrency = 4
+
+//DocumentOptions 文档OCR选项
+type DocumentOptions struct {
+	Endpoint    string //识别接口: "generalocr"(默认), "bizlicenseocr"或"driverlicenseocr"
+	LicenseType int32  //driverlicenseocr的类型, 0代表行驶证, 1代表驾驶证
+	Concurrency int    //同时识别的页数, 小于等于0时使用DefaultDocumentConcurrency
+	Seq         string //序列号, 所有页相同
+}
+
+//DocumentPage 文档的一页
+type DocumentPage struct {
+	Number int           //页码, 从1开始
+	Input  int           //该页所在输入图片的序号, 从0开始
+	Frame  int           //该页在输入图片中的页或帧序号, 从0开始
+	Result GeneralOcrRsp //识别结果, 坐标相对于该页
+	Err    error         //该页识别失败的原因
+}
+
+//Text 该页识别出的文本, 每个识别项一行
+func (p DocumentPage) Text() string {
+	lines := make([]string, 0, len(p.Result.Items))
+	for _, it := range p.Result.Items {
+		lines = append(lines, it.Itemstring)
+	}
+	return strings.Join(lines, "\n")
+}
+
+//Document 文档OCR结果
+type Document struct {
+	Pages []DocumentPage
+}
+
+//Text 按页码合并的文本, 页之间以换页符分隔
+func (d Document) Text() string {
+	texts := make([]string, len(d.Pages))
+	for i, p := range d.Pages {
+		texts[i] = p.Text()
+	}
+	return strings.Join(texts, "\n\f\n")
+}
+
+//Err 第一个识别失败的页的错误
+func (d Document) Err() error {
+	for _, p := range d.Pages {
+		if p.Err != nil {
+			return fmt.Errorf("page %d: %w", p.Number, p.Err)
+		}
+	}
+	return nil
+}
+
+//DocumentOcr 识别多页文档. inputs中的多页TIFF和多帧GIF按页拆分, 每页在识别前才解码并转换为JPEG,
+//其它图片(包括url)各为一页. 各页并发识别, 单页解码或识别失败不影响其它页, 记录在页的Err中,
+//err为拆分失败或第一个失败页的错误
+func (y *Youtu) DocumentOcr(inputs []Image, o DocumentOptions) (doc Document, err error) {
+	if len(inputs) == 0 {
+		return doc, invalid("Image", "no pages")
+	}
+	var recognize func(Image) (GeneralOcrRsp, error)
+	switch o.Endpoint {
+	case "", "generalocr":
+		recognize = func(img Image) (GeneralOcrRsp, error) {
+			return y.GeneralOcrRequest(GeneralOcrRequest{Image: img, Seq: o.Seq})
+		}
+	case "bizlicenseocr":
+		recognize = func(img Image) (GeneralOcrRsp, error) {
+			return y.BizLicenseOcrRequest(BizLicenseOcrRequest{Image: img, Seq: o.Seq})
+		}
+	case "driverlicenseocr":
+		recognize = func(img Image) (rsp GeneralOcrRsp, err error) {
+			r, err := y.DriverLicenseOcrRequest(DriverLicenseOcrRequest{Image: img, Type: o.LicenseType, Seq: o.Seq})
+			rsp = GeneralOcrRsp{SessionId: r.SessionId, Items: r.Items, ErrorCode: r.ErrorCode, ErrorMsg: r.ErrorMsg}
+			return
+		}
+	default:
+		return doc, invalid("Endpoint", fmt.Sprintf("%q does not support documents", o.Endpoint))
+	}
+
+	var loaders []pageLoader
+	for i, in := range inputs {
+		pages, err := splitDocument(in)
+		if err != nil {
+			return doc, fmt.Errorf("input %d: %w", i, err)
+		}
+		for f, load := range pages {
+			loaders = append(loaders, load)
+			doc.Pages = append(doc.Pages, DocumentPage{Number: len(doc.Pages) + 1, Input: i, Frame: f})
+		}
+	}
+
+	n := o.Concurrency
+	if n <= 0 {
+		n = DefaultDocumentConcurrency
+	}
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i := range doc.Pages {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p *DocumentPage, load pageLoader) {
+			defer func() { <-sem; wg.Done() }()
+			img, err := load()
+			if err != nil {
+				p.Err = err
+				return
+			}
+			p.Result, p.Err = recognize(img)
+		}(&doc.Pages[i], loaders[i])
+	}
+	wg.Wait()
+	err = doc.Err()
+	return
+}
+
+//pageLoader 加载文档的一页, 识别该页时才调用, 同时解码的页数不超过并发数
+type pageLoader func() (Image, error)
+
+//splitDocument 把多页TIFF和多帧GIF拆成页, 每页加载时才解码并转换为JPEG, 其它图片原样作为一页
+func splitDocument(img Image) ([]pageLoader, error) {
+	whole := []pageLoader{func() (Image, error) { return img, nil }}
+	if img.IsURL() || img.std != nil || img.empty() {
+		return whole, nil
+	}
+	data, err := img.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var n int
+	var frame func(i int) (image.Image, error)
+	switch {
+	case isTIFF(data):
+		//只解析IFD, 各页的像素在加载时解码
+		ifds, err := parseTIFF(data)
+		if err != nil {
+			return nil, err
+		}
+		n = len(ifds)
+		frame = func(i int) (image.Image, error) {
+			m, err := ifds[i].decode()
+			if err != nil {
+				return nil, fmt.Errorf("tiff page %d: %w", i+1, err)
+			}
+			return m, nil
+		}
+	case SniffImageFormat(data) == FormatGIF:
+		//调色板帧需要一次解码, 合成和JPEG编码在加载时进行
+		if err := checkDecodeSize(data); err != nil {
+			return nil, err
+		}
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil || len(g.Image) < 2 {
+			return whole, nil
+		}
+		n = len(g.Image)
+		frame = func(i int) (image.Image, error) { return gifFrame(g, i), nil }
+	default:
+		return whole, nil
+	}
+	pages := make([]pageLoader, n)
+	for i := range pages {
+		i := i
+		pages[i] = func() (Image, error) {
+			m, err := frame(i)
+			if err != nil {
+				return Image{}, err
+			}
+			return ImageJPEG(toRGBA(m), DefaultNormalizeQuality), nil
+		}
+	}
+	return pages, nil
+}
+
+//gifFrame 按处置方式依次合成GIF的前n+1帧, 返回第n帧的画面
+func gifFrame(g *gif.GIF, n int) image.Image {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewNRGBA(bounds)
+	for i, m := range g.Image[:n+1] {
+		var saved *image.NRGBA
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			saved = image.NewNRGBA(bounds)
+			copy(saved.Pix, canvas.Pix)
+		}
+		draw.Draw(canvas, m.Bounds(), m, m.Bounds().Min, draw.Over)
+		if i == n {
+			break
+		}
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, m.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = saved
+		}
+	}
+	return canvas
+}
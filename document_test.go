@@ -0,0 +1,105 @@
+/*
+* File Name:	document_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDocumentOcr(t *testing.T) {
+	var mu sync.Mutex
+	inflight, maxInflight := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inflight++
+		if inflight > maxInflight {
+			maxInflight = inflight
+		}
+		mu.Unlock()
+		defer func() { mu.Lock(); inflight--; mu.Unlock() }()
+		time.Sleep(10 * time.Millisecond)
+
+		var req GeneralOcrReq
+		json.NewDecoder(r.Body).Decode(&req)
+		data, _ := base64.StdEncoding.DecodeString(req.Image)
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			w.Write([]byte(`{"errorcode":-1,"errormsg":"bad image"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(GeneralOcrRsp{Items: []ItemContent{{Itemstring: fmt.Sprintf("%s %dx%d", format, cfg.Width, cfg.Height)}}})
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	pal := []color.Color{color.Black, color.White}
+	var anim bytes.Buffer
+	gif.EncodeAll(&anim, &gif.GIF{
+		Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 70, 70), pal), image.NewPaletted(image.Rect(10, 10, 20, 20), pal)},
+		Delay: []int{0, 0},
+	})
+	inputs := []Image{ImageBytes(testTIFF(64, 64, 3, 32773, false)), ImageBytes(testJPEG(80, 80)), ImageBytes(anim.Bytes())}
+	doc, err := y.DocumentOcr(inputs, DocumentOptions{Concurrency: 2})
+	if err != nil {
+		t.Errorf("DocumentOcr failed: %s\n", err)
+		return
+	}
+	want := []struct {
+		input, frame int
+		text         string
+	}{
+		{0, 0, "jpeg 64x64"}, {0, 1, "jpeg 64x64"}, {0, 2, "jpeg 64x64"},
+		{1, 0, "jpeg 80x80"},
+		{2, 0, "jpeg 70x70"}, {2, 1, "jpeg 70x70"},
+	}
+	if len(doc.Pages) != len(want) {
+		t.Errorf("DocumentOcr returned %d pages, want %d\n", len(doc.Pages), len(want))
+		return
+	}
+	for i, p := range doc.Pages {
+		if p.Number != i+1 || p.Input != want[i].input || p.Frame != want[i].frame || p.Text() != want[i].text {
+			t.Errorf("page %d = %d, %d, %d, %q\n", i, p.Number, p.Input, p.Frame, p.Text())
+		}
+	}
+	if doc.Text() != "jpeg 64x64\n\f\njpeg 64x64\n\f\njpeg 64x64\n\f\njpeg 80x80\n\f\njpeg 70x70\n\f\njpeg 70x70" {
+		t.Errorf("Document.Text = %q\n", doc.Text())
+	}
+	if maxInflight > 2 {
+		t.Errorf("%d pages recognized concurrently, want at most 2\n", maxInflight)
+	}
+
+	//单页失败记录在页中, 其它页仍然识别
+	bad := []Image{ImageBytes(testJPEG(64, 64)), ImageBytes([]byte("not an image"))}
+	doc, err = y.DocumentOcr(bad, DocumentOptions{})
+	if err == nil || len(doc.Pages) != 2 || doc.Pages[0].Err != nil || doc.Pages[1].Err == nil {
+		t.Errorf("DocumentOcr with a bad page = %v, %+v\n", err, doc.Pages)
+	}
+
+	//TIFF各页在识别时才解码, 坏页只影响自己
+	tiff := testTIFF(64, 64, 2, 1, false)
+	bps := []byte{tiffBitsPerSample & 0xff, tiffBitsPerSample >> 8, 3, 0, 1, 0, 0, 0, 8, 0}
+	tiff[bytes.LastIndex(tiff, bps)+8] = 3
+	doc, err = y.DocumentOcr([]Image{ImageBytes(tiff)}, DocumentOptions{})
+	if err == nil || len(doc.Pages) != 2 || doc.Pages[0].Err != nil || doc.Pages[1].Err == nil {
+		t.Errorf("DocumentOcr with a bad TIFF page = %v, %+v\n", err, doc.Pages)
+	}
+
+	if _, err := y.DocumentOcr(inputs, DocumentOptions{Endpoint: "idcardocr"}); err == nil {
+		t.Errorf("DocumentOcr accepted an unsupported endpoint\n")
+	}
+}
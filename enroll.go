@@ -0,0 +1,237 @@
+/*
+* File Name:	enroll.go
+* Description:  入库质量评估: 综合人脸检测、模糊检测和五官定位打分, 拒绝不合格的入库图片
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+//入库质量不合格的原因
+const (
+	EnrollNoFace        = "no-face"        //没有检测到人脸
+	EnrollMultipleFaces = "multiple-faces" //多于一张人脸
+	EnrollFaceTooSmall  = "face-too-small" //人脸太小
+	EnrollPose          = "pose"           //俯仰、偏转或旋转角度过大
+	EnrollGlasses       = "glasses"        //戴眼镜(策略要求不戴时)
+	EnrollBlurry        = "blurry"         //FuzzyDetect判为模糊
+	EnrollLandmarks     = "landmarks"      //五官定位点不完整
+	EnrollLowScore      = "low-score"      //综合得分低于MinScore
+)
+
+//faceShapePoints 五官定位的点数
+const faceShapePoints = 88
+
+//ErrEnrollRejected 图片没有通过入库质量检查
+var ErrEnrollRejected = errors.New("enrollment rejected")
+
+//EnrollPolicy 入库质量策略, 值为0的项使用默认值
+type EnrollPolicy struct {
+	MinFaceRatio   float64 //人脸宽度至少占图片短边的比例, 默认0.2
+	MinFaceSize    float64 //人脸宽度的最小像素数, 默认80
+	MaxPitch       int32   //俯仰角绝对值上限, 默认20
+	MaxYaw         int32   //偏转角绝对值上限, 默认20
+	MaxRoll        int32   //平面旋转角绝对值上限, 默认25
+	RejectGlasses  bool    //拒绝戴眼镜的人脸
+	SkipBlur       bool    //不调用FuzzyDetect
+	CheckLandmarks bool    //调用FaceShape检查五官定位点是否完整
+	MinScore       float64 //综合得分下限(0~1), 默认0.5
+}
+
+func (p EnrollPolicy) withDefaults() EnrollPolicy {
+	if p.MinFaceRatio <= 0 {
+		p.MinFaceRatio = 0.2
+	}
+	if p.MinFaceSize <= 0 {
+		p.MinFaceSize = 80
+	}
+	if p.MaxPitch <= 0 {
+		p.MaxPitch = 20
+	}
+	if p.MaxYaw <= 0 {
+		p.MaxYaw = 20
+	}
+	if p.MaxRoll <= 0 {
+		p.MaxRoll = 25
+	}
+	if p.MinScore <= 0 {
+		p.MinScore = 0.5
+	}
+	return p
+}
+
+//EnrollQuality 一张图片的入库质量
+type EnrollQuality struct {
+	Score           float64  //综合得分(0~1), 各项得分之积
+	Reasons         []string //不合格的原因, 为空表示通过
+	Faces           int      //检测到的人脸数
+	Face            Face     //唯一的人脸, Faces不为1时为零值
+	FaceRatio       float64  //人脸宽度与图片短边之比
+	FuzzyConfidence float32  //FuzzyDetect的模糊参考值, SkipBlur时为0
+	Landmarks       int      //图片范围内的五官定位点数, 不检查时为-1
+}
+
+//OK 是否通过
+func (q EnrollQuality) OK() bool {
+	return len(q.Reasons) == 0
+}
+
+//Err 没有通过时返回包装了ErrEnrollRejected的错误
+func (q EnrollQuality) Err() error {
+	if q.OK() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (score %.2f)", ErrEnrollRejected, strings.Join(q.Reasons, ", "), q.Score)
+}
+
+//rspError 接口返回码不为0时的错误
+func rspError(ifname string, errorCode int, errorMsg string) error {
+	return fmt.Errorf("%s: errorcode %d %s", ifname, errorCode, errorMsg)
+}
+
+//angleScore 角度a在上限max内的得分, 0度为1, 达到上限为0.5, 超过上限继续线性下降
+func angleScore(a, max int32) float64 {
+	return math.Max(0, 1-math.Abs(float64(a))/float64(2*max))
+}
+
+//EvaluateEnrollment 按策略p评估img是否适合入库. 依次调用DetectFace, FuzzyDetect(除非SkipBlur)
+//和FaceShape(CheckLandmarks时), 检测不到唯一人脸时不再调用后面的接口
+func (y *Youtu) EvaluateEnrollment(img Image, p EnrollPolicy) (q EnrollQuality, err error) {
+	p = p.withDefaults()
+	q.Landmarks = -1
+	det, err := y.DetectFaceImage(img, false)
+	if err != nil {
+		return
+	}
+	if det.ErrorCode != 0 {
+		return q, rspError("detectface", det.ErrorCode, det.ErrorMsg)
+	}
+	q.Faces = len(det.Face)
+	switch q.Faces {
+	case 0:
+		q.Reasons = []string{EnrollNoFace}
+		return
+	case 1:
+	default:
+		q.Reasons = []string{EnrollMultipleFaces}
+		return
+	}
+	f := det.Face[0]
+	q.Face = f
+	q.Score = 1
+
+	short := math.Min(float64(det.ImageWidth), float64(det.ImageHeight))
+	if short > 0 {
+		q.FaceRatio = float64(f.Width) / short
+	}
+	q.Score *= math.Min(1, q.FaceRatio/p.MinFaceRatio) * math.Min(1, float64(f.Width)/p.MinFaceSize)
+	if q.FaceRatio < p.MinFaceRatio || float64(f.Width) < p.MinFaceSize {
+		q.Reasons = append(q.Reasons, EnrollFaceTooSmall)
+	}
+
+	q.Score *= angleScore(f.Pitch, p.MaxPitch) * angleScore(f.Yaw, p.MaxYaw) * angleScore(f.Roll, p.MaxRoll)
+	if abs32(f.Pitch) > p.MaxPitch || abs32(f.Yaw) > p.MaxYaw || abs32(f.Roll) > p.MaxRoll {
+		q.Reasons = append(q.Reasons, EnrollPose)
+	}
+
+	if p.RejectGlasses && f.Glass {
+		q.Score *= 0.8
+		q.Reasons = append(q.Reasons, EnrollGlasses)
+	}
+
+	if !p.SkipBlur {
+		var fz FuzzyDetectRsp
+		if fz, err = y.FuzzyDetectImage(img, ""); err != nil {
+			return
+		}
+		if fz.ErrorCode != 0 {
+			return q, rspError("fuzzydetect", int(fz.ErrorCode), fz.ErrorMsg)
+		}
+		q.FuzzyConfidence = fz.FuzzyConfidence
+		q.Score *= 1 - math.Min(1, math.Max(0, float64(fz.FuzzyConfidence)))
+		if fz.Fuzzy {
+			q.Reasons = append(q.Reasons, EnrollBlurry)
+		}
+	}
+
+	if p.CheckLandmarks {
+		var fs FaceShapeRsp
+		if fs, err = y.FaceShapeImage(img, false); err != nil {
+			return
+		}
+		if fs.ErrorCode != 0 {
+			return q, rspError("faceshape", fs.ErrorCode, fs.ErrorMsg)
+		}
+		q.Landmarks = 0
+		if len(fs.FaceShape) == 1 {
+			bounds := RectXYWH(0, 0, float64(fs.ImageWidth), float64(fs.ImageHeight))
+			for _, pt := range fs.FaceShape[0].Points() {
+				if pt.X >= bounds.Min.X && pt.Y >= bounds.Min.Y && pt.X <= bounds.Max.X && pt.Y <= bounds.Max.Y {
+					q.Landmarks++
+				}
+			}
+		}
+		q.Score *= float64(q.Landmarks) / faceShapePoints
+		if q.Landmarks < faceShapePoints {
+			q.Reasons = append(q.Reasons, EnrollLandmarks)
+		}
+	}
+
+	if q.Score < p.MinScore {
+		q.Reasons = append(q.Reasons, EnrollLowScore)
+	}
+	return
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+//EnrollSafely 评估r.Image, 通过时才调用NewPerson. 没有通过时返回q.Err(), 可用errors.Is判断ErrEnrollRejected
+func (y *Youtu) EnrollSafely(r NewPersonRequest, p EnrollPolicy) (rsp NewPersonRsp, q EnrollQuality, err error) {
+	if err = validateRequest("newperson", r); err != nil {
+		return
+	}
+	if q, err = y.EvaluateEnrollment(r.Image, p); err != nil {
+		return
+	}
+	if err = q.Err(); err != nil {
+		return
+	}
+	rsp, err = y.NewPersonRequest(r)
+	return
+}
+
+//AddFaceSafely 逐张评估r.Images, 只把通过的图片加入个体. qs与r.Images一一对应,
+//没有图片通过时返回第一张图片的q.Err()
+func (y *Youtu) AddFaceSafely(r AddFaceRequest, p EnrollPolicy) (rsp AddFaceRsp, qs []EnrollQuality, err error) {
+	if err = validateRequest("addface", r); err != nil {
+		return
+	}
+	qs = make([]EnrollQuality, len(r.Images))
+	var ok []Image
+	for i, img := range r.Images {
+		if qs[i], err = y.EvaluateEnrollment(img, p); err != nil {
+			return
+		}
+		if qs[i].OK() {
+			ok = append(ok, img)
+		}
+	}
+	if len(ok) == 0 {
+		err = qs[0].Err()
+		return
+	}
+	r.Images = ok
+	rsp, err = y.AddFaceRequest(r)
+	return
+}
@@ -0,0 +1,161 @@
+/*
+* File Name:	enroll_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+)
+
+//enrollScene 假服务器对一张图片返回的检测结果
+type enrollScene struct {
+	faces  string
+	fuzzy  bool
+	points int
+}
+
+//sceneImage 宽度为40+n的图片, 假服务器按宽度选择场景n
+func sceneImage(n int) Image {
+	return ImagePNG(image.NewGray(image.Rect(0, 0, 40+n, 40)))
+}
+
+//enrollServer 按图片宽度返回scenes中的场景, 并记录调用的接口
+func enrollServer(scenes map[int]enrollScene, calls *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		ifname := path.Base(r.URL.Path)
+		*calls = append(*calls, ifname)
+		var sc enrollScene
+		if s, ok := body["image"].(string); ok {
+			data, _ := base64.StdEncoding.DecodeString(s)
+			cfg, _, _ := image.DecodeConfig(bytes.NewReader(data))
+			sc = scenes[cfg.Width-40]
+		}
+		switch ifname {
+		case "detectface":
+			fmt.Fprintf(w, `{"errorcode":0,"image_width":640,"image_height":480,"face":[%s]}`, sc.faces)
+		case "fuzzydetect":
+			fmt.Fprintf(w, `{"errorcode":0,"fuzzy":%v,"fuzzy_confidence":%v}`, sc.fuzzy, map[bool]float64{true: 0.9, false: 0.1}[sc.fuzzy])
+		case "faceshape":
+			pts := make([]string, sc.points)
+			for i := range pts {
+				pts[i] = `{"x":300,"y":200}`
+			}
+			all := strings.Join(pts, ",")
+			fmt.Fprintf(w, `{"errorcode":0,"image_width":640,"image_height":480,"face_shape":[{"face_profile":[%s]}]}`, all)
+		case "addface":
+			fmt.Fprintf(w, `{"errorcode":0,"added":%d}`, len(body["images"].([]interface{})))
+		default:
+			fmt.Fprint(w, `{"errorcode":0}`)
+		}
+	}))
+}
+
+func TestEvaluateEnrollment(t *testing.T) {
+	const (
+		good = iota + 1
+		none
+		two
+		small
+		turn
+		blur
+		glass
+		cut
+	)
+	face := `{"x":200,"y":100,"width":200,"height":220,"pitch":2,"yaw":-5,"roll":1}`
+	scenes := map[int]enrollScene{
+		good:  {faces: face, points: 88},
+		none:  {},
+		two:   {faces: face + "," + face},
+		small: {faces: `{"x":10,"y":10,"width":60,"height":60}`},
+		turn:  {faces: `{"x":200,"y":100,"width":200,"height":220,"yaw":35}`},
+		blur:  {faces: face, fuzzy: true},
+		glass: {faces: `{"x":200,"y":100,"width":200,"height":220,"glass":true}`},
+		cut:   {faces: face, points: 40},
+	}
+	var calls []string
+	srv := enrollServer(scenes, &calls)
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	for _, c := range []struct {
+		scene  int
+		policy EnrollPolicy
+		want   []string
+	}{
+		{good, EnrollPolicy{CheckLandmarks: true}, nil},
+		{none, EnrollPolicy{}, []string{EnrollNoFace}},
+		{two, EnrollPolicy{}, []string{EnrollMultipleFaces}},
+		{small, EnrollPolicy{}, []string{EnrollFaceTooSmall, EnrollLowScore}},
+		{turn, EnrollPolicy{}, []string{EnrollPose, EnrollLowScore}},
+		{blur, EnrollPolicy{}, []string{EnrollBlurry, EnrollLowScore}},
+		{blur, EnrollPolicy{SkipBlur: true}, nil},
+		{glass, EnrollPolicy{}, nil},
+		{glass, EnrollPolicy{RejectGlasses: true}, []string{EnrollGlasses}},
+		{cut, EnrollPolicy{CheckLandmarks: true}, []string{EnrollLandmarks, EnrollLowScore}},
+	} {
+		q, err := y.EvaluateEnrollment(sceneImage(c.scene), c.policy)
+		if err != nil {
+			t.Errorf("EvaluateEnrollment(scene %d): %v\n", c.scene, err)
+			continue
+		}
+		if strings.Join(q.Reasons, ",") != strings.Join(c.want, ",") {
+			t.Errorf("EvaluateEnrollment(scene %d) reasons = %v, want %v (score %.2f)\n", c.scene, q.Reasons, c.want, q.Score)
+		}
+		if q.OK() != (q.Err() == nil) || (!q.OK() && !errors.Is(q.Err(), ErrEnrollRejected)) {
+			t.Errorf("EvaluateEnrollment(scene %d) Err = %v\n", c.scene, q.Err())
+		}
+	}
+
+	//检测不到唯一人脸时不再调用后面的接口
+	calls = nil
+	y.EvaluateEnrollment(sceneImage(two), EnrollPolicy{CheckLandmarks: true})
+	if len(calls) != 1 {
+		t.Errorf("calls for two faces = %v\n", calls)
+	}
+}
+
+func TestEnrollSafely(t *testing.T) {
+	const good, blur = 1, 2
+	scenes := map[int]enrollScene{
+		good: {faces: `{"x":200,"y":100,"width":200,"height":220}`},
+		blur: {faces: `{"x":200,"y":100,"width":200,"height":220}`, fuzzy: true},
+	}
+	var calls []string
+	srv := enrollServer(scenes, &calls)
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	_, q, err := y.EnrollSafely(NewPersonRequest{PersonID: "p1", GroupIDs: []string{"g"}, Image: sceneImage(blur)}, EnrollPolicy{})
+	if !errors.Is(err, ErrEnrollRejected) || q.OK() || contains(calls, "newperson") {
+		t.Errorf("EnrollSafely with blurry image: %v, calls %v\n", err, calls)
+	}
+	calls = nil
+	if _, _, err := y.EnrollSafely(NewPersonRequest{PersonID: "p1", GroupIDs: []string{"g"}, Image: sceneImage(good)}, EnrollPolicy{}); err != nil || !contains(calls, "newperson") {
+		t.Errorf("EnrollSafely with good image: %v, calls %v\n", err, calls)
+	}
+
+	images := []Image{sceneImage(blur), sceneImage(good), sceneImage(good)}
+	rsp, qs, err := y.AddFaceSafely(AddFaceRequest{PersonID: "p1", Images: images}, EnrollPolicy{})
+	if err != nil || rsp.Added != 2 || len(qs) != 3 || qs[0].OK() || !qs[1].OK() {
+		t.Errorf("AddFaceSafely = %+v, %d qualities, %v\n", rsp, len(qs), err)
+	}
+	calls = nil
+	if _, _, err := y.AddFaceSafely(AddFaceRequest{PersonID: "p1", Images: images[:1]}, EnrollPolicy{}); !errors.Is(err, ErrEnrollRejected) || contains(calls, "addface") {
+		t.Errorf("AddFaceSafely with only rejected images: %v\n", err)
+	}
+}
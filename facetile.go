@@ -0,0 +1,217 @@
+/*
+* File Name:	facetile.go
+* Description:  人群照片的分块人脸检测: 重叠分块(可放大)并发检测, 映射回整图并做非极大值抑制
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"image"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	//DefaultFaceTileSize 人脸分块的默认边长
+	DefaultFaceTileSize = 800
+	//DefaultFaceTileOverlap 相邻人脸分块默认的重叠宽度, 应大于要检测的最大人脸
+	DefaultFaceTileOverlap = 160
+	//DefaultFaceNMSThreshold 非极大值抑制的默认IoU阈值
+	DefaultFaceNMSThreshold = 0.3
+	//faceCutOverlap 交集占较小框的比例超过该值时, 视为分块边界切开的同一张脸
+	faceCutOverlap = 0.8
+)
+
+//FaceTileOptions 分块人脸检测选项, 值为0的项使用默认值
+type FaceTileOptions struct {
+	TileSize     int     //分块边长(原图像素)
+	Overlap      int     //相邻分块的重叠宽度, 不超过TileSize的一半
+	Upscale      float64 //分块发送前的放大倍数, 大于1时放大, 用于检测很小的人脸
+	Concurrency  int     //同时检测的分块数, 默认DefaultDocumentConcurrency
+	NMSThreshold float64 //两个人脸框IoU超过该值时只保留一个
+}
+
+func (o FaceTileOptions) withDefaults() FaceTileOptions {
+	if o.TileSize <= 0 {
+		o.TileSize = DefaultFaceTileSize
+	}
+	if o.Overlap <= 0 {
+		o.Overlap = DefaultFaceTileOverlap
+	}
+	if o.Overlap > o.TileSize/2 {
+		o.Overlap = o.TileSize / 2
+	}
+	if o.Upscale < 1 {
+		o.Upscale = 1
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultDocumentConcurrency
+	}
+	if o.NMSThreshold <= 0 {
+		o.NMSThreshold = DefaultFaceNMSThreshold
+	}
+	return o
+}
+
+//FaceTile 一个分块的检测情况
+type FaceTile struct {
+	Rect      image.Rectangle //分块在整图中的位置
+	Scale     float64         //发送时的放大倍数
+	Faces     int             //分块中检测到的人脸数
+	Kept      int             //抑制重复后保留的人脸数
+	ErrorCode int             //分块请求的errorcode
+	ErrorMsg  string          //分块请求的errormsg
+	Err       error           //分块请求的错误
+	Elapsed   time.Duration   //分块请求的耗时
+}
+
+//TiledDetectFaceRsp 分块人脸检测结果, 人脸框相对于摆正后的整图
+type TiledDetectFaceRsp struct {
+	DetectFaceRsp
+	Tiles []FaceTile //各分块的检测情况, 按行优先排列
+}
+
+//tileFace 分块检测出的人脸及其到分块内侧边界的距离
+type tileFace struct {
+	face   Face
+	tile   int
+	rect   Rect
+	margin float64 //到不在图片边界上的分块边的最近距离与人脸边长之比, 越大越完整
+}
+
+//DetectFaceTiled 分块检测大图中的小人脸, 适合合影和人群照片.
+//图片在本地解码并按EXIF摆正, 各分块的人脸映射回整图后去掉重复: 没有置信度可用,
+//重复的人脸中保留离分块内侧边界最远(最不可能被切开)的一个, 其次保留较大的.
+//部分分块失败时仍返回其它分块的结果, 所有分块都失败时返回第一个失败的错误或errorcode
+func (y *Youtu) DetectFaceTiled(img Image, o FaceTileOptions) (rsp TiledDetectFaceRsp, err error) {
+	o = o.withDefaults()
+	m, t, err := y.decodeImage("detectface", img)
+	if err != nil {
+		return
+	}
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	rects := tileRects(w, h, o.TileSize, o.Overlap)
+	results := make([]DetectFaceRsp, len(rects))
+	rsp.Tiles = make([]FaceTile, len(rects))
+	sem := make(chan struct{}, o.Concurrency)
+	var wg sync.WaitGroup
+	for i, r := range rects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, r image.Rectangle) {
+			defer func() { <-sem; wg.Done() }()
+			tile := &rsp.Tiles[i]
+			tile.Rect, tile.Scale = r, o.Upscale
+			sub := toRGBA(m.SubImage(r))
+			pt := TranslateTransform(float64(-r.Min.X), float64(-r.Min.Y))
+			if o.Upscale > 1 {
+				sw, sh := int(math.Round(float64(r.Dx())*o.Upscale)), int(math.Round(float64(r.Dy())*o.Upscale))
+				pt = pt.Then(ScaleTransform(float64(sw)/float64(r.Dx()), float64(sh)/float64(r.Dy())))
+				sub = resize(sub, sw, sh)
+			}
+			start := time.Now()
+			results[i], tile.Err = y.DetectFaceRequest(DetectFaceRequest{Image: ImageJPEG(sub, DefaultNormalizeQuality).WithTransform(pt)})
+			tile.Elapsed = time.Since(start)
+			tile.ErrorCode, tile.ErrorMsg = results[i].ErrorCode, results[i].ErrorMsg
+		}(i, r)
+	}
+	wg.Wait()
+
+	var faces []tileFace
+	ok := false
+	for i, r := range results {
+		tile := &rsp.Tiles[i]
+		if tile.Err != nil || tile.ErrorCode != 0 {
+			continue
+		}
+		ok = true
+		rsp.SessionID = r.SessionID
+		orig := r.Original()
+		tile.Faces = len(orig.Face)
+		for _, f := range orig.Face {
+			faces = append(faces, tileFace{face: f, tile: i, rect: f.Rect(), margin: tileMargin(f.Rect(), tile.Rect, w, h)})
+		}
+	}
+	if !ok {
+		for _, tile := range rsp.Tiles {
+			if tile.Err != nil {
+				return rsp, tile.Err
+			}
+		}
+		rsp.ErrorCode, rsp.ErrorMsg = rsp.Tiles[0].ErrorCode, rsp.Tiles[0].ErrorMsg
+		return
+	}
+
+	for _, f := range suppressFaces(faces, o.NMSThreshold) {
+		rsp.Face = append(rsp.Face, f.face)
+		rsp.Tiles[f.tile].Kept++
+	}
+	rsp.ImageWidth, rsp.ImageHeight = int32(w), int32(h)
+	if !t.IsIdentity() {
+		rsp.Transform = &t
+	}
+	return
+}
+
+//tileMargin 人脸框到分块内侧边(不在w*h图片边界上的边)的最近距离与人脸边长之比
+func tileMargin(f Rect, tile image.Rectangle, w, h int) float64 {
+	margin := math.Inf(1)
+	if tile.Min.X > 0 {
+		margin = math.Min(margin, f.Min.X-float64(tile.Min.X))
+	}
+	if tile.Min.Y > 0 {
+		margin = math.Min(margin, f.Min.Y-float64(tile.Min.Y))
+	}
+	if tile.Max.X < w {
+		margin = math.Min(margin, float64(tile.Max.X)-f.Max.X)
+	}
+	if tile.Max.Y < h {
+		margin = math.Min(margin, float64(tile.Max.Y)-f.Max.Y)
+	}
+	if size := math.Max(f.Dx(), f.Dy()); size > 0 {
+		margin /= size
+	}
+	return margin
+}
+
+//suppressFaces 非极大值抑制, 按完整程度和大小排序后去掉与已保留人脸重复的框.
+//同一分块内的人脸只按IoU比较, 不同分块之间还把被切开的部分框视为重复
+func suppressFaces(faces []tileFace, threshold float64) []tileFace {
+	sort.SliceStable(faces, func(i, j int) bool {
+		a, b := faces[i], faces[j]
+		if a.margin != b.margin {
+			return a.margin > b.margin
+		}
+		return a.rect.Area() > b.rect.Area()
+	})
+	var kept []tileFace
+	for _, f := range faces {
+		dup := false
+		for _, k := range kept {
+			if f.rect.IoU(k.rect) > threshold {
+				dup = true
+				break
+			}
+			if small := math.Min(f.rect.Area(), k.rect.Area()); f.tile != k.tile && small > 0 &&
+				f.rect.Intersect(k.rect).Area()/small >= faceCutOverlap {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			kept = append(kept, f)
+		}
+	}
+	//按从上到下、从左到右输出
+	sort.SliceStable(kept, func(i, j int) bool {
+		a, b := kept[i].rect, kept[j].rect
+		if a.Min.Y != b.Min.Y {
+			return a.Min.Y < b.Min.Y
+		}
+		return a.Min.X < b.Min.X
+	})
+	return kept
+}
@@ -0,0 +1,84 @@
+/*
+* File Name:	facetile_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetectFaceTiled(t *testing.T) {
+	//x<600黑, 其余白, 服务器按分块左上角的颜色区分分块, 按分块宽度推算放大倍数
+	m := image.NewRGBA(image.Rect(0, 0, 1400, 600))
+	draw.Draw(m, m.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(m, image.Rect(600, 0, 1400, 600), image.NewUniform(color.White), image.Point{}, draw.Src)
+	failAll := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req detectFaceReq
+		json.NewDecoder(r.Body).Decode(&req)
+		data, _ := base64.StdEncoding.DecodeString(req.Image)
+		tile, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil || failAll {
+			w.Write([]byte(`{"errorcode":-1101,"errormsg":"no face"}`))
+			return
+		}
+		s := float32(tile.Bounds().Dx()) / 800
+		face := func(x, y, w, h float32) Face {
+			return Face{X: int32(x * s), Y: int32(y * s), Width: w * s, Height: h * s, Age: int32(x)}
+		}
+		rsp := DetectFaceRsp{ImageWidth: int32(tile.Bounds().Dx()), ImageHeight: int32(tile.Bounds().Dy())}
+		if c, _, _, _ := tile.At(0, 0).RGBA(); c < 0x8000 {
+			rsp.Face = []Face{face(100, 100, 80, 80), face(740, 200, 60, 80)}
+		} else {
+			rsp.Face = []Face{face(500, 300, 80, 80), face(140, 200, 100, 80)}
+		}
+		json.NewEncoder(w).Encode(rsp)
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	for _, upscale := range []float64{0, 2} {
+		rsp, err := y.DetectFaceTiled(ImagePNG(m), FaceTileOptions{Upscale: upscale})
+		if err != nil || rsp.ErrorCode != 0 {
+			t.Errorf("DetectFaceTiled(upscale %v) = %d, %v\n", upscale, rsp.ErrorCode, err)
+			continue
+		}
+		want := []Rect{RectXYWH(100, 100, 80, 80), RectXYWH(740, 200, 100, 80), RectXYWH(1100, 300, 80, 80)}
+		if len(rsp.Face) != len(want) {
+			t.Errorf("DetectFaceTiled(upscale %v) faces = %+v\n", upscale, rsp.Face)
+			continue
+		}
+		for i, f := range rsp.Face {
+			if f.Rect() != want[i] {
+				t.Errorf("DetectFaceTiled(upscale %v) face %d = %v, want %v\n", upscale, i, f.Rect(), want[i])
+			}
+		}
+		if rsp.ImageWidth != 1400 || rsp.ImageHeight != 600 || len(rsp.Tiles) != 2 {
+			t.Errorf("DetectFaceTiled(upscale %v) size %dx%d, %d tiles\n", upscale, rsp.ImageWidth, rsp.ImageHeight, len(rsp.Tiles))
+			continue
+		}
+		if tl := rsp.Tiles[0]; tl.Rect != image.Rect(0, 0, 800, 600) || tl.Faces != 2 || tl.Kept != 1 {
+			t.Errorf("tile 0 = %+v\n", tl)
+		}
+		if tl := rsp.Tiles[1]; tl.Rect != image.Rect(600, 0, 1400, 600) || tl.Faces != 2 || tl.Kept != 2 {
+			t.Errorf("tile 1 = %+v\n", tl)
+		}
+	}
+
+	failAll = true
+	rsp, err := y.DetectFaceTiled(ImagePNG(m), FaceTileOptions{})
+	if err != nil || rsp.ErrorCode != -1101 || len(rsp.Face) != 0 {
+		t.Errorf("DetectFaceTiled with all tiles failing = %d, %v\n", rsp.ErrorCode, err)
+	}
+}
@@ -0,0 +1,352 @@
+/*
+* File Name:	fetch.go
+* Description:  SDK下载URL图片后上传图片数据, 用于服务器无法访问的内网、签名或短期有效的url
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+//URLMode URL图片的发送方式
+type URLMode int
+
+const (
+	//URLServer url交给服务器下载
+	URLServer URLMode = iota
+	//URLFetch SDK下载后上传图片数据
+	URLFetch
+	//URLFallback 先交给服务器下载, 服务器返回DownloadFailedCodes时由SDK下载后重试一次
+	URLFallback
+)
+
+const (
+	//DefaultFetchMaxBytes 下载图片的默认最大字节数
+	DefaultFetchMaxBytes = 10 << 20
+	//DefaultFetchTimeout 下载图片的默认超时
+	DefaultFetchTimeout = 10 * time.Second
+)
+
+//DefaultDownloadFailedCodes 服务器表示url图片下载失败的errorcode
+var DefaultDownloadFailedCodes = []int{-1308}
+
+var (
+	//ErrFetchDenied url的主机或解析到的地址不允许访问
+	ErrFetchDenied = errors.New("address not allowed")
+	//ErrFetchTooLarge 下载的图片超过MaxBytes
+	ErrFetchTooLarge = errors.New("image too large")
+)
+
+//URLFetchOptions SDK下载URL图片的选项, 值为0的项使用默认值.
+//默认拒绝访问回环、私有、链路本地(含云主机元数据地址)等内网地址, 内网url需要在AllowNets中放行.
+//地址在连接时按实际解析结果检查, 重定向的每一跳都会重新检查
+type URLFetchOptions struct {
+	Mode                URLMode
+	MaxBytes            int64         //下载的最大字节数
+	Timeout             time.Duration //整个下载的超时
+	ContentTypes        []string      //允许的Content-Type, 为空时允许image/*和application/octet-stream
+	AllowHosts          []string      //非空时只允许这些主机, "example.com"精确匹配, ".example.com"匹配其子域名
+	DenyHosts           []string      //拒绝的主机, 格式同AllowHosts
+	AllowNets           []string      //放行的网段(CIDR), 如"10.1.0.0/16"
+	DenyNets            []string      //额外拒绝的网段(CIDR), 优先于AllowNets
+	DownloadFailedCodes []int         //URLFallback模式下表示服务器下载失败的errorcode, 为空时使用DefaultDownloadFailedCodes
+}
+
+//fetcher 按URLFetchOptions下载图片
+type fetcher struct {
+	opts      URLFetchOptions
+	allowNets []*net.IPNet
+	denyNets  []*net.IPNet
+	client    *http.Client
+}
+
+//blockedNets 默认拒绝的网段
+var blockedNets = mustCIDRs(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/3",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func mustCIDRs(cidrs ...string) []*net.IPNet {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		panic(err)
+	}
+	return nets
+}
+
+func inNets(ip net.IP, nets []*net.IPNet) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+//SetURLFetch 设置URL图片的下载方式, o为nil时恢复为由服务器下载
+func (y *Youtu) SetURLFetch(o *URLFetchOptions) error {
+	var f *fetcher
+	if o != nil && o.Mode != URLServer {
+		var err error
+		if f, err = newFetcher(*o); err != nil {
+			return err
+		}
+	}
+	y.shared.mu.Lock()
+	y.shared.fetcher = f
+	y.shared.mu.Unlock()
+	return nil
+}
+
+func (y *Youtu) urlFetcher() *fetcher {
+	y.shared.mu.RLock()
+	defer y.shared.mu.RUnlock()
+	return y.shared.fetcher
+}
+
+func newFetcher(o URLFetchOptions) (f *fetcher, err error) {
+	f = &fetcher{opts: o}
+	if f.allowNets, err = parseCIDRs(o.AllowNets); err != nil {
+		return nil, err
+	}
+	if f.denyNets, err = parseCIDRs(o.DenyNets); err != nil {
+		return nil, err
+	}
+	if f.opts.MaxBytes <= 0 {
+		f.opts.MaxBytes = DefaultFetchMaxBytes
+	}
+	if f.opts.Timeout <= 0 {
+		f.opts.Timeout = DefaultFetchTimeout
+	}
+	if len(f.opts.DownloadFailedCodes) == 0 {
+		f.opts.DownloadFailedCodes = DefaultDownloadFailedCodes
+	}
+	dialer := &net.Dialer{
+		Timeout: f.opts.Timeout,
+		//连接前检查实际要连接的地址, 防止DNS解析到内网
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !f.ipAllowed(ip) {
+				return ErrFetchDenied
+			}
+			return nil
+		},
+	}
+	f.client = &http.Client{
+		//不使用代理, 否则检查的是代理的地址
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f, nil
+}
+
+func (f *fetcher) ipAllowed(ip net.IP) bool {
+	if inNets(ip, f.denyNets) {
+		return false
+	}
+	if inNets(ip, f.allowNets) {
+		return true
+	}
+	return !inNets(ip, blockedNets)
+}
+
+func hostMatch(host string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if host == p || (strings.HasPrefix(p, ".") && strings.HasSuffix(host, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrFetchDenied
+	}
+	host := strings.ToLower(u.Hostname())
+	if hostMatch(host, f.opts.DenyHosts) {
+		return ErrFetchDenied
+	}
+	if len(f.opts.AllowHosts) > 0 && !hostMatch(host, f.opts.AllowHosts) {
+		return ErrFetchDenied
+	}
+	return nil
+}
+
+func (f *fetcher) contentTypeAllowed(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	if len(f.opts.ContentTypes) == 0 {
+		return strings.HasPrefix(mt, "image/") || mt == "application/octet-stream"
+	}
+	for _, t := range f.opts.ContentTypes {
+		if strings.EqualFold(t, mt) {
+			return true
+		}
+	}
+	return false
+}
+
+//safeURL 去掉url中的查询参数和用户信息, 签名url的参数不出现在错误信息中
+func safeURL(u *url.URL) string {
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
+
+//fetch 下载图片数据
+func (f *fetcher) fetch(rawurl string) (data []byte, err error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, invalid("Image", "malformed url")
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("fetch %s: %w", safeURL(u), err)
+		}
+	}()
+	if err = f.checkURL(u); err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			//url.Error含完整url, 只保留原因
+			err = ue.Err
+		}
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: resp.StatusCode}
+	}
+	if ct := resp.Header.Get("Content-Type"); !f.contentTypeAllowed(ct) {
+		return nil, fmt.Errorf("content type %q not allowed", ct)
+	}
+	if resp.ContentLength > f.opts.MaxBytes {
+		return nil, ErrFetchTooLarge
+	}
+	data, err = ioutil.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes+1))
+	if err == nil && int64(len(data)) > f.opts.MaxBytes {
+		return nil, ErrFetchTooLarge
+	}
+	return
+}
+
+func (f *fetcher) downloadFailed(errorCode int) bool {
+	if f.opts.Mode != URLFallback || errorCode == 0 {
+		return false
+	}
+	for _, c := range f.opts.DownloadFailedCodes {
+		if c == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+//urlImageFields 请求中url字段和对应的图片数据字段
+var urlImageFields = [][2]string{{"url", "image"}, {"urlA", "imageA"}, {"urlB", "imageB"}}
+
+//fetchFallback 下载请求data中的url图片, 返回改为上传图片数据的请求.
+//请求中没有url时ok为false. 图片做格式转换并按接口限制校验, 但不做预处理, 以免结果坐标与记录的变换不符
+func (y *Youtu) fetchFallback(f *fetcher, ifname string, data []byte) (out []byte, ok bool, err error) {
+	var req map[string]json.RawMessage
+	if err = json.Unmarshal(data, &req); err != nil {
+		return
+	}
+	limits := y.ImageLimitsFor(ifname)
+	load := func(u string) (string, error) {
+		b, err := f.fetch(u)
+		if err == nil {
+			b, err = y.normalize(ifname, b)
+		}
+		if err != nil {
+			return "", err
+		}
+		if err = withEndpoint(ifname, checkImageData(limits, b)); err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+	for _, kv := range urlImageFields {
+		var u string
+		if raw, has := req[kv[0]]; !has || json.Unmarshal(raw, &u) != nil || u == "" {
+			continue
+		}
+		img, err := load(u)
+		if err != nil {
+			return nil, false, err
+		}
+		req[kv[1]], _ = json.Marshal(img)
+		delete(req, kv[0])
+		ok = true
+	}
+	var urls, images []string
+	if raw, has := req["urls"]; has && json.Unmarshal(raw, &urls) == nil && len(urls) > 0 {
+		json.Unmarshal(req["images"], &images)
+		for _, u := range urls {
+			img, err := load(u)
+			if err != nil {
+				return nil, false, err
+			}
+			images = append(images, img)
+		}
+		req["images"], _ = json.Marshal(images)
+		delete(req, "urls")
+		ok = true
+	}
+	if !ok {
+		return
+	}
+	if y.debug {
+		fmt.Fprintf(os.Stderr, "%s: server failed to download url, retry with fetched image\n", ifname)
+	}
+	out, err = json.Marshal(req)
+	return
+}
@@ -0,0 +1,129 @@
+/*
+* File Name:	fetch_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//newImageServer 返回提供/a.jpg图片的服务器, 其它路径按路径名返回相应内容
+func newImageServer(data []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(data)
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write(data)
+		case "/redirect":
+			http.Redirect(w, r, strings.Replace(r.Host, "127.0.0.1", "http://localhost", 1)+"/a.jpg", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestURLFetch(t *testing.T) {
+	raw := testJPEG(64, 64)
+	imgSrv := newImageServer(raw)
+	defer imgSrv.Close()
+	var bodies []map[string]interface{}
+	srv := newEchoServer(&bodies)
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	//默认拒绝回环地址, 错误信息中不含签名参数
+	y.SetURLFetch(&URLFetchOptions{Mode: URLFetch})
+	_, err := y.DetectFaceImage(ImageURL(imgSrv.URL+"/a.jpg?sig=s3cr3t"), false)
+	if !errors.Is(err, ErrFetchDenied) || strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("fetch from loopback: err = %v\n", err)
+	}
+
+	if err := y.SetURLFetch(&URLFetchOptions{Mode: URLFetch, AllowNets: []string{"bad"}}); err == nil {
+		t.Errorf("SetURLFetch accepted a malformed CIDR\n")
+	}
+	y.SetURLFetch(&URLFetchOptions{Mode: URLFetch, AllowNets: []string{"127.0.0.0/8"}, DenyHosts: []string{"localhost"}})
+	if _, err := y.DetectFaceImage(ImageURL(imgSrv.URL+"/a.jpg"), false); err != nil {
+		t.Errorf("DetectFaceImage with fetched url failed: %s\n", err)
+		return
+	}
+	if len(bodies) != 1 || bodies[0]["image"] != base64.StdEncoding.EncodeToString(raw) || bodies[0]["url"] != nil {
+		t.Errorf("fetched image not uploaded as data: %v\n", bodies)
+	}
+	for path, want := range map[string]string{
+		"/page.html": "content type",
+		"/missing":   "404",
+		"/redirect":  ErrFetchDenied.Error(),
+	} {
+		if _, err := y.DetectFaceImage(ImageURL(imgSrv.URL+path), false); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("fetch %s: err = %v, want %q\n", path, err, want)
+		}
+	}
+
+	y.SetURLFetch(&URLFetchOptions{Mode: URLFetch, AllowNets: []string{"127.0.0.0/8"}, MaxBytes: 100})
+	if _, err := y.DetectFaceImage(ImageURL(imgSrv.URL+"/a.jpg"), false); !errors.Is(err, ErrFetchTooLarge) {
+		t.Errorf("fetch over MaxBytes: err = %v\n", err)
+	}
+	y.SetURLFetch(&URLFetchOptions{Mode: URLFetch, AllowNets: []string{"127.0.0.0/8"}, AllowHosts: []string{".example.com"}})
+	if _, err := y.DetectFaceImage(ImageURL(imgSrv.URL+"/a.jpg"), false); !errors.Is(err, ErrFetchDenied) {
+		t.Errorf("fetch from host outside AllowHosts: err = %v\n", err)
+	}
+}
+
+func TestURLFetchFallback(t *testing.T) {
+	raw := testJPEG(64, 64)
+	imgSrv := newImageServer(raw)
+	defer imgSrv.Close()
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if body["url"] != nil || body["urls"] != nil {
+			w.Write([]byte(`{"errorcode":-1308,"errormsg":"download failed"}`))
+			return
+		}
+		w.Write([]byte(`{"errorcode":0}`))
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+	y.SetURLFetch(&URLFetchOptions{Mode: URLFallback, AllowNets: []string{"127.0.0.0/8"}})
+
+	u := imgSrv.URL + "/a.jpg"
+	rsp, err := y.DetectFaceImage(ImageURL(u), false)
+	if err != nil || rsp.ErrorCode != 0 {
+		t.Errorf("DetectFaceImage fallback = %d, %v\n", rsp.ErrorCode, err)
+		return
+	}
+	if len(bodies) != 2 || bodies[0]["url"] != u || bodies[1]["image"] != base64.StdEncoding.EncodeToString(raw) {
+		t.Errorf("fallback requests = %v\n", bodies)
+	}
+
+	bodies = nil
+	add, err := y.AddFaceImages("p", []Image{ImageBytes(raw), ImageURL(u)}, "")
+	if err != nil || add.ErrorCode != 0 {
+		t.Errorf("AddFaceImages fallback = %d, %v\n", add.ErrorCode, err)
+		return
+	}
+	if images, _ := bodies[1]["images"].([]interface{}); len(images) != 2 || bodies[1]["urls"] != nil {
+		t.Errorf("AddFaceImages fallback body = %v\n", bodies[1])
+	}
+
+	//URLServer模式不重试
+	y.SetURLFetch(nil)
+	bodies = nil
+	if rsp, _ := y.DetectFaceImage(ImageURL(u), false); rsp.ErrorCode != -1308 || len(bodies) != 1 {
+		t.Errorf("URLServer mode retried: errorcode %d, %d requests\n", rsp.ErrorCode, len(bodies))
+	}
+}
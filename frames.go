@@ -0,0 +1,268 @@
+/*
+* File Name:	frames.go
+* Description:  从连拍、图片目录或MJPEG视频中挑选姿态各异的最佳帧, 用NewPerson和AddFace入库
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	//DefaultFrameCount 默认挑选的帧数
+	DefaultFrameCount = 5
+	//DefaultFramePoseDelta 两帧俯仰、偏转和旋转角之差的和小于该值时视为姿态相同
+	DefaultFramePoseDelta = 10
+)
+
+//frameExts FramesFromDir读取的文件扩展名
+var frameExts = []string{".jpg", ".jpeg", ".png", ".bmp", ".gif", ".tif", ".tiff"}
+
+//FramesFromDir 按文件名顺序读取目录中的图片文件, 不读取子目录
+func FramesFromDir(dir string) (frames []Image, err error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, fi := range infos {
+		if fi.Mode().IsRegular() && contains(frameExts, strings.ToLower(filepath.Ext(fi.Name()))) {
+			frames = append(frames, ImageFile(filepath.Join(dir, fi.Name())))
+		}
+	}
+	return
+}
+
+//FramesFromMJPEG 把MJPEG(首尾相接的JPEG图片)拆成帧
+func FramesFromMJPEG(r io.Reader) (frames []Image, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	jpegs, err := splitMJPEG(data)
+	for _, b := range jpegs {
+		frames = append(frames, ImageBytes(b))
+	}
+	return
+}
+
+//splitMJPEG 按JPEG段结构找到每帧的结尾, 不会被EXIF缩略图中的SOI/EOI干扰
+func splitMJPEG(data []byte) (frames [][]byte, err error) {
+	for i := 0; i < len(data); {
+		j := bytes.Index(data[i:], []byte{0xff, 0xd8})
+		if j < 0 {
+			break
+		}
+		start := i + j
+		end, err := jpegEnd(data, start)
+		if err != nil {
+			return frames, fmt.Errorf("MJPEG frame %d: %w", len(frames), err)
+		}
+		frames = append(frames, data[start:end])
+		i = end
+	}
+	if len(frames) == 0 {
+		err = errors.New("no JPEG frames in MJPEG data")
+	}
+	return
+}
+
+//jpegEnd 返回从p处SOI开始的JPEG图片EOI之后的位置
+func jpegEnd(data []byte, p int) (int, error) {
+	p += 2
+	for p+1 < len(data) {
+		if data[p] != 0xff {
+			return 0, errors.New("corrupt JPEG marker")
+		}
+		m := data[p+1]
+		switch {
+		case m == 0xff:
+			p++
+			continue
+		case m == 0xd9:
+			return p + 2, nil
+		case m == 0x01 || m >= 0xd0 && m <= 0xd7:
+			p += 2
+			continue
+		}
+		if p+3 >= len(data) {
+			break
+		}
+		p += 2 + (int(data[p+2])<<8 | int(data[p+3]))
+		if m == 0xda {
+			//跳过熵编码数据, 其中的0xff后面只会是0或RSTn
+			for p+1 < len(data) && (data[p] != 0xff || data[p+1] == 0 || data[p+1] >= 0xd0 && data[p+1] <= 0xd7) {
+				p++
+			}
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+//FrameOptions 挑选帧的选项, 值为0的项使用默认值
+type FrameOptions struct {
+	Count         int          //挑选的帧数, 默认DefaultFrameCount
+	Policy        EnrollPolicy //评估每帧的入库策略
+	FuzzyDetect   bool         //每帧都调用FuzzyDetect(Policy.SkipBlur时不调用), 默认只用本地清晰度判断模糊
+	BlurThreshold float64      //本地清晰度低于该值的帧判为模糊, 默认DefaultBlurThreshold
+	PoseDelta     float64      //姿态差小于该值的帧只选一张, 不够Count张时再补充, 默认DefaultFramePoseDelta
+	Concurrency   int          //同时评估的帧数, 默认DefaultDocumentConcurrency
+}
+
+func (o FrameOptions) withDefaults() FrameOptions {
+	if o.Count <= 0 {
+		o.Count = DefaultFrameCount
+	}
+	if o.BlurThreshold <= 0 {
+		o.BlurThreshold = DefaultBlurThreshold
+	}
+	if o.PoseDelta <= 0 {
+		o.PoseDelta = DefaultFramePoseDelta
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultDocumentConcurrency
+	}
+	if !o.FuzzyDetect {
+		o.Policy.SkipBlur = true
+	}
+	return o
+}
+
+//FrameScore 一帧的评估结果
+type FrameScore struct {
+	Index     int           //在输入中的序号
+	Quality   EnrollQuality //入库质量, 本地判为模糊时Reasons包含EnrollBlurry
+	Sharpness float64       //本地清晰度
+	Score     float64       //Quality.Score乘以清晰度得分Sharpness/(Sharpness+BlurThreshold)
+	Err       error         //解码或接口调用失败的原因
+}
+
+//OK 是否可以入库
+func (f FrameScore) OK() bool {
+	return f.Err == nil && f.Quality.OK()
+}
+
+//BestFrames 挑选结果
+type BestFrames struct {
+	Frames   []FrameScore //与输入一一对应
+	Selected []int        //选中帧的序号, 按Score从高到低
+}
+
+//poseDistance 两张人脸俯仰、偏转和旋转角之差的和
+func poseDistance(a, b Face) float64 {
+	return math.Abs(float64(a.Pitch-b.Pitch)) + math.Abs(float64(a.Yaw-b.Yaw)) + math.Abs(float64(a.Roll-b.Roll))
+}
+
+//SelectFrames 并发评估每一帧(DetectFace的人脸数、大小和姿态, 本地清晰度), 在可以入库的帧中
+//按得分挑选姿态各异的Count帧. 先跳过与已选帧姿态相近的帧, 不够时再按得分补充
+func (y *Youtu) SelectFrames(frames []Image, o FrameOptions) (b BestFrames, err error) {
+	if len(frames) == 0 {
+		return b, invalid("Image", "no frames")
+	}
+	o = o.withDefaults()
+	b.Frames = make([]FrameScore, len(frames))
+	sem := make(chan struct{}, o.Concurrency)
+	var wg sync.WaitGroup
+	for i := range frames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(f *FrameScore, img Image) {
+			defer func() { <-sem; wg.Done() }()
+			if f.Sharpness, f.Err = y.ImageSharpness(img); f.Err != nil {
+				return
+			}
+			if f.Quality, f.Err = y.EvaluateEnrollment(img, o.Policy); f.Err != nil {
+				return
+			}
+			if f.Sharpness < o.BlurThreshold && !contains(f.Quality.Reasons, EnrollBlurry) {
+				f.Quality.Reasons = append(f.Quality.Reasons, EnrollBlurry)
+			}
+			f.Score = f.Quality.Score * f.Sharpness / (f.Sharpness + o.BlurThreshold)
+		}(&b.Frames[i], frames[i])
+		b.Frames[i].Index = i
+	}
+	wg.Wait()
+
+	var candidates []int
+	for i, f := range b.Frames {
+		if f.OK() {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return b.Frames[candidates[i]].Score > b.Frames[candidates[j]].Score
+	})
+	taken := make(map[int]bool)
+	for _, i := range candidates {
+		if len(b.Selected) == o.Count {
+			break
+		}
+		diverse := true
+		for _, s := range b.Selected {
+			if poseDistance(b.Frames[i].Quality.Face, b.Frames[s].Quality.Face) < o.PoseDelta {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			b.Selected = append(b.Selected, i)
+			taken[i] = true
+		}
+	}
+	for _, i := range candidates {
+		if len(b.Selected) == o.Count {
+			break
+		}
+		if !taken[i] {
+			b.Selected = append(b.Selected, i)
+		}
+	}
+	sort.SliceStable(b.Selected, func(i, j int) bool {
+		return b.Frames[b.Selected[i]].Score > b.Frames[b.Selected[j]].Score
+	})
+	return
+}
+
+//EnrollFrames 用SelectFrames挑选帧, 得分最高的一帧调用NewPerson创建个体(忽略r.Image),
+//其余帧用AddFace加入. 没有可以入库的帧时返回包装了ErrEnrollRejected的错误
+func (y *Youtu) EnrollFrames(r NewPersonRequest, frames []Image, o FrameOptions) (b BestFrames, person NewPersonRsp, added AddFaceRsp, err error) {
+	if b, err = y.SelectFrames(frames, o); err != nil {
+		return
+	}
+	if len(b.Selected) == 0 {
+		err = fmt.Errorf("%w: no usable frame among %d", ErrEnrollRejected, len(frames))
+		return
+	}
+	r.Image = frames[b.Selected[0]]
+	if person, err = y.NewPersonRequest(r); err != nil {
+		return
+	}
+	if person.ErrorCode != 0 {
+		err = rspError("newperson", person.ErrorCode, person.ErrorMsg)
+		return
+	}
+	if len(b.Selected) == 1 {
+		return
+	}
+	var rest []Image
+	for _, i := range b.Selected[1:] {
+		rest = append(rest, frames[i])
+	}
+	if added, err = y.AddFaceRequest(AddFaceRequest{PersonID: r.PersonID, Images: rest, Tag: r.Tag}); err != nil {
+		return
+	}
+	if added.ErrorCode != 0 {
+		err = rspError("addface", added.ErrorCode, added.ErrorMsg)
+	}
+	return
+}
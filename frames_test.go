@@ -0,0 +1,143 @@
+/*
+* File Name:	frames_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFramesFromMJPEG(t *testing.T) {
+	var stream bytes.Buffer
+	for _, w := range []int{50, 60, 70} {
+		jpeg.Encode(&stream, blurredImage(w, 40, 0), nil)
+		stream.WriteString("\r\n--frame\r\n")
+	}
+	frames, err := FramesFromMJPEG(&stream)
+	if err != nil || len(frames) != 3 {
+		t.Errorf("FramesFromMJPEG = %d frames, %v\n", len(frames), err)
+		return
+	}
+	for i, f := range frames {
+		data, _ := f.Bytes()
+		m, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil || m.Bounds().Dx() != 50+10*i {
+			t.Errorf("frame %d: %v\n", i, err)
+		}
+	}
+	if _, err := FramesFromMJPEG(bytes.NewReader([]byte("no frames"))); err == nil {
+		t.Errorf("FramesFromMJPEG accepted data without frames\n")
+	}
+	var cut bytes.Buffer
+	jpeg.Encode(&cut, blurredImage(50, 40, 0), nil)
+	if _, err := FramesFromMJPEG(bytes.NewReader(cut.Bytes()[:cut.Len()/2])); err == nil {
+		t.Errorf("FramesFromMJPEG accepted a truncated frame\n")
+	}
+}
+
+func TestFramesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.png", "a.JPG", "notes.txt"} {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+	frames, err := FramesFromDir(dir)
+	if err != nil || len(frames) != 2 {
+		t.Errorf("FramesFromDir = %d frames, %v\n", len(frames), err)
+		return
+	}
+	if data, _ := frames[0].Bytes(); string(data) != "a.JPG" {
+		t.Errorf("first frame = %q, want a.JPG\n", data)
+	}
+}
+
+func TestSelectFrames(t *testing.T) {
+	const (
+		front = iota + 1
+		front2
+		left
+		right
+		two
+		small
+		blurry
+	)
+	scenes := map[int]enrollScene{
+		front:  {faces: `{"x":10,"y":10,"width":200,"height":200}`},
+		front2: {faces: `{"x":10,"y":10,"width":200,"height":200,"yaw":2}`},
+		left:   {faces: `{"x":10,"y":10,"width":200,"height":200,"yaw":-12}`},
+		right:  {faces: `{"x":10,"y":10,"width":200,"height":200,"yaw":15}`},
+		two:    {faces: `{"x":10,"y":10,"width":200,"height":200},{"x":300,"y":10,"width":200,"height":200}`},
+		small:  {faces: `{"x":10,"y":10,"width":30,"height":30}`},
+		blurry: {faces: `{"x":10,"y":10,"width":200,"height":200}`},
+	}
+	var calls []string
+	srv := enrollServer(scenes, &calls)
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	var frames []Image
+	for _, n := range []int{small, front2, right, two, front, left, blurry} {
+		r := 0
+		if n == blurry {
+			r = 8
+		}
+		frames = append(frames, ImagePNG(blurredImage(40+n, 40, r)))
+	}
+	thr := (Sharpness(blurredImage(47, 40, 0)) + Sharpness(blurredImage(47, 40, 8))) / 2
+	o := FrameOptions{Count: 3, BlurThreshold: thr}
+	b, err := y.SelectFrames(frames, o)
+	if err != nil || len(b.Frames) != 7 {
+		t.Errorf("SelectFrames: %v\n", err)
+		return
+	}
+	if contains(calls, "fuzzydetect") {
+		t.Errorf("SelectFrames called fuzzydetect without FuzzyDetect\n")
+	}
+	calls = nil
+	y.SelectFrames(frames, FrameOptions{FuzzyDetect: true, Policy: EnrollPolicy{SkipBlur: true}})
+	if contains(calls, "fuzzydetect") {
+		t.Errorf("SelectFrames called fuzzydetect with Policy.SkipBlur\n")
+	}
+	//正脸得分最高, 姿态相近的front2让位于左右侧脸
+	if want := []int{4, 5, 2}; !equalInts(b.Selected, want) {
+		t.Errorf("Selected = %v, want %v\n", b.Selected, want)
+	}
+	if !contains(b.Frames[6].Quality.Reasons, EnrollBlurry) || b.Frames[3].OK() || b.Frames[0].OK() {
+		t.Errorf("rejected frames = %+v\n", b.Frames)
+	}
+	//不够时补充姿态相近的帧
+	o.Count = 10
+	if b, _ = y.SelectFrames(frames, o); !equalInts(b.Selected, []int{4, 1, 5, 2}) {
+		t.Errorf("Selected with Count 10 = %v\n", b.Selected)
+	}
+
+	calls = nil
+	o.Count = 3
+	_, person, added, err := y.EnrollFrames(NewPersonRequest{PersonID: "p1", GroupIDs: []string{"g"}}, frames, o)
+	if err != nil || person.ErrorCode != 0 || added.Added != 2 || !contains(calls, "newperson") {
+		t.Errorf("EnrollFrames = %+v, %v, calls %v\n", added, err, calls)
+	}
+	if _, _, _, err := y.EnrollFrames(NewPersonRequest{PersonID: "p1", GroupIDs: []string{"g"}}, frames[3:4], o); !errors.Is(err, ErrEnrollRejected) {
+		t.Errorf("EnrollFrames without usable frames: %v\n", err)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,442 @@
+/*
+* File Name:	geometry.go
+* Description:  统一的点、矩形和多边形, 以及各返回结构体的几何访问方法
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"image"
+	"math"
+)
+
+//Point 图片中的点, 单位为像素
+type Point struct {
+	X, Y float64
+}
+
+//Pt 返回点(x, y)
+func Pt(x, y float64) Point {
+	return Point{x, y}
+}
+
+//PointFrom 由image.Point转换
+func PointFrom(p image.Point) Point {
+	return Point{float64(p.X), float64(p.Y)}
+}
+
+//ImagePoint 四舍五入为image.Point
+func (p Point) ImagePoint() image.Point {
+	return image.Point{roundInt(p.X), roundInt(p.Y)}
+}
+
+//Scale 按sx, sy缩放
+func (p Point) Scale(sx, sy float64) Point {
+	return Point{p.X * sx, p.Y * sy}
+}
+
+//Rect 轴对齐的矩形, 包含Min, 不包含Max
+type Rect struct {
+	Min, Max Point
+}
+
+//RectXYWH 由左上角和宽高创建矩形
+func RectXYWH(x, y, w, h float64) Rect {
+	return Rect{Point{x, y}, Point{x + w, y + h}}
+}
+
+//RectFrom 由image.Rectangle转换
+func RectFrom(r image.Rectangle) Rect {
+	return Rect{PointFrom(r.Min), PointFrom(r.Max)}
+}
+
+//ImageRect 四舍五入为image.Rectangle
+func (r Rect) ImageRect() image.Rectangle {
+	return image.Rectangle{r.Min.ImagePoint(), r.Max.ImagePoint()}
+}
+
+//Dx 宽度
+func (r Rect) Dx() float64 {
+	return r.Max.X - r.Min.X
+}
+
+//Dy 高度
+func (r Rect) Dy() float64 {
+	return r.Max.Y - r.Min.Y
+}
+
+//Empty 面积是否为0
+func (r Rect) Empty() bool {
+	return r.Min.X >= r.Max.X || r.Min.Y >= r.Max.Y
+}
+
+//Area 面积, 空矩形为0
+func (r Rect) Area() float64 {
+	if r.Empty() {
+		return 0
+	}
+	return r.Dx() * r.Dy()
+}
+
+//Center 中心点
+func (r Rect) Center() Point {
+	return Point{(r.Min.X + r.Max.X) / 2, (r.Min.Y + r.Max.Y) / 2}
+}
+
+//Intersect 交集, 不相交时返回空矩形
+func (r Rect) Intersect(s Rect) Rect {
+	i := Rect{
+		Point{math.Max(r.Min.X, s.Min.X), math.Max(r.Min.Y, s.Min.Y)},
+		Point{math.Min(r.Max.X, s.Max.X), math.Min(r.Max.Y, s.Max.Y)},
+	}
+	if i.Empty() {
+		return Rect{}
+	}
+	return i
+}
+
+//Union 包含两个矩形的最小矩形
+func (r Rect) Union(s Rect) Rect {
+	if r.Empty() {
+		return s
+	}
+	if s.Empty() {
+		return r
+	}
+	return Rect{
+		Point{math.Min(r.Min.X, s.Min.X), math.Min(r.Min.Y, s.Min.Y)},
+		Point{math.Max(r.Max.X, s.Max.X), math.Max(r.Max.Y, s.Max.Y)},
+	}
+}
+
+//IoU 交并比, 范围0~1
+func (r Rect) IoU(s Rect) float64 {
+	inter := r.Intersect(s).Area()
+	union := r.Area() + s.Area() - inter
+	if union <= 0 {
+		return 0
+	}
+	return inter / union
+}
+
+//Contains 是否包含点p
+func (r Rect) Contains(p Point) bool {
+	return p.X >= r.Min.X && p.X < r.Max.X && p.Y >= r.Min.Y && p.Y < r.Max.Y
+}
+
+//ContainsRect 是否完全包含s
+func (r Rect) ContainsRect(s Rect) bool {
+	return s.Min.X >= r.Min.X && s.Max.X <= r.Max.X && s.Min.Y >= r.Min.Y && s.Max.Y <= r.Max.Y
+}
+
+//Scale 按sx, sy缩放, 原点不变
+func (r Rect) Scale(sx, sy float64) Rect {
+	return Rect{r.Min.Scale(sx, sy), r.Max.Scale(sx, sy)}
+}
+
+//Inset 四边各向内收缩n, 为负数时向外扩展
+func (r Rect) Inset(n float64) Rect {
+	return Rect{Point{r.Min.X + n, r.Min.Y + n}, Point{r.Max.X - n, r.Max.Y - n}}
+}
+
+//Clip 裁剪到宽w高h的图片范围内
+func (r Rect) Clip(w, h int) Rect {
+	return r.Intersect(RectXYWH(0, 0, float64(w), float64(h)))
+}
+
+//Polygon 返回四个角组成的多边形, 顺时针
+func (r Rect) Polygon() Polygon {
+	return Polygon{r.Min, {r.Max.X, r.Min.Y}, r.Max, {r.Min.X, r.Max.Y}}
+}
+
+//Polygon 多边形, 顶点按顺序连接
+type Polygon []Point
+
+//ImagePoints 四舍五入为image.Point
+func (p Polygon) ImagePoints() []image.Point {
+	pts := make([]image.Point, len(p))
+	for i, pt := range p {
+		pts[i] = pt.ImagePoint()
+	}
+	return pts
+}
+
+//Bounds 外接矩形
+func (p Polygon) Bounds() Rect {
+	if len(p) == 0 {
+		return Rect{}
+	}
+	r := Rect{p[0], p[0]}
+	for _, pt := range p[1:] {
+		r.Min.X, r.Max.X = math.Min(r.Min.X, pt.X), math.Max(r.Max.X, pt.X)
+		r.Min.Y, r.Max.Y = math.Min(r.Min.Y, pt.Y), math.Max(r.Max.Y, pt.Y)
+	}
+	return r
+}
+
+//Area 面积
+func (p Polygon) Area() float64 {
+	var a float64
+	for i := range p {
+		j := (i + 1) % len(p)
+		a += p[i].X*p[j].Y - p[j].X*p[i].Y
+	}
+	return math.Abs(a) / 2
+}
+
+//Contains 点pt是否在多边形内
+func (p Polygon) Contains(pt Point) bool {
+	in := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			in = !in
+		}
+	}
+	return in
+}
+
+//Scale 按sx, sy缩放
+func (p Polygon) Scale(sx, sy float64) Polygon {
+	out := make(Polygon, len(p))
+	for i, pt := range p {
+		out[i] = pt.Scale(sx, sy)
+	}
+	return out
+}
+
+//Clip 裁剪到宽w高h的图片范围内
+func (p Polygon) Clip(w, h int) Polygon {
+	return p.clipConvex(RectXYWH(0, 0, float64(w), float64(h)).Polygon())
+}
+
+//IoU 交并比, 两个多边形都必须是凸多边形
+func (p Polygon) IoU(q Polygon) float64 {
+	inter := p.clipConvex(q).Area()
+	union := p.Area() + q.Area() - inter
+	if union <= 0 {
+		return 0
+	}
+	return inter / union
+}
+
+//clipConvex 用凸多边形c裁剪p(Sutherland-Hodgman)
+func (p Polygon) clipConvex(c Polygon) Polygon {
+	if len(c) < 3 {
+		return nil
+	}
+	//让c的方向一致, 内侧在边的同一边
+	var s float64
+	for i := range c {
+		j := (i + 1) % len(c)
+		s += c[i].X*c[j].Y - c[j].X*c[i].Y
+	}
+	side := func(a, b, pt Point) float64 {
+		v := (b.X-a.X)*(pt.Y-a.Y) - (b.Y-a.Y)*(pt.X-a.X)
+		if s < 0 {
+			return -v
+		}
+		return v
+	}
+	out := append(Polygon(nil), p...)
+	for i := range c {
+		a, b := c[i], c[(i+1)%len(c)]
+		in := out
+		out = nil
+		for k := range in {
+			cur, prev := in[k], in[(k+len(in)-1)%len(in)]
+			dc, dp := side(a, b, cur), side(a, b, prev)
+			if dc >= 0 {
+				if dp < 0 {
+					out = append(out, lerp(prev, cur, dp/(dp-dc)))
+				}
+				out = append(out, cur)
+			} else if dp >= 0 {
+				out = append(out, lerp(prev, cur, dp/(dp-dc)))
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+	}
+	return out
+}
+
+func lerp(a, b Point, t float64) Point {
+	return Point{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+}
+
+//Rect 人脸框
+func (f Face) Rect() Rect {
+	return RectXYWH(float64(f.X), float64(f.Y), float64(f.Width), float64(f.Height))
+}
+
+//Point 五官定位点
+func (p pos) Point() Point {
+	return Point{float64(p.X), float64(p.Y)}
+}
+
+func posPolygon(ps []pos) Polygon {
+	out := make(Polygon, len(ps))
+	for i, p := range ps {
+		out[i] = p.Point()
+	}
+	return out
+}
+
+//Polygons 按部位返回五官轮廓, 键与JSON字段名相同, 如"left_eye"
+func (s FaceShape) Polygons() map[string]Polygon {
+	return map[string]Polygon{
+		"face_profile":  posPolygon(s.FaceProfile),
+		"left_eye":      posPolygon(s.LeftEye),
+		"right_eye":     posPolygon(s.RightEye),
+		"left_eyebrow":  posPolygon(s.LeftEyebrow),
+		"right_eyebrow": posPolygon(s.RightEyebrow),
+		"mouth":         posPolygon(s.Mouth),
+		"nose":          posPolygon(s.Nose),
+	}
+}
+
+//Points 全部88个定位点
+func (s FaceShape) Points() []Point {
+	var pts []Point
+	for _, ps := range [][]pos{s.FaceProfile, s.LeftEye, s.RightEye, s.LeftEyebrow, s.RightEyebrow, s.Mouth, s.Nose} {
+		pts = append(pts, posPolygon(ps)...)
+	}
+	return pts
+}
+
+//Bounds 所有定位点的外接矩形
+func (s FaceShape) Bounds() Rect {
+	return Polygon(s.Points()).Bounds()
+}
+
+//Rect 人脸框
+func (r FaceRectItem) Rect() Rect {
+	return RectXYWH(float64(r.X), float64(r.Y), float64(r.Width), float64(r.Height))
+}
+
+//Rect 文字框
+func (c Coordinate) Rect() Rect {
+	return RectXYWH(float64(c.X), float64(c.Y), float64(c.Width), float64(c.Height))
+}
+
+//Rect 文字框Itemcoord
+func (it ItemContent) Rect() Rect {
+	return it.Itemcoord.Rect()
+}
+
+//CharRects 单字框Coords
+func (it ItemContent) CharRects() []Rect {
+	rs := make([]Rect, len(it.Coords))
+	for i, c := range it.Coords {
+		rs[i] = c.Rect()
+	}
+	return rs
+}
+
+//Rect 车辆或车标框
+func (c CarCoordinate) Rect() Rect {
+	return RectXYWH(float64(c.X), float64(c.Y), float64(c.Width), float64(c.Height))
+}
+
+//Bounds 请求图片的范围
+func (rsp DetectFaceRsp) Bounds() Rect {
+	return RectXYWH(0, 0, float64(rsp.ImageWidth), float64(rsp.ImageHeight))
+}
+
+//Rects 所有人脸框
+func (rsp DetectFaceRsp) Rects() []Rect {
+	rs := make([]Rect, len(rsp.Face))
+	for i, f := range rsp.Face {
+		rs[i] = f.Rect()
+	}
+	return rs
+}
+
+//Bounds 请求图片的范围
+func (rsp FaceShapeRsp) Bounds() Rect {
+	return RectXYWH(0, 0, float64(rsp.ImageWidth), float64(rsp.ImageHeight))
+}
+
+//Rects 每个人脸所有定位点的外接矩形
+func (rsp FaceShapeRsp) Rects() []Rect {
+	rs := make([]Rect, len(rsp.FaceShape))
+	for i, s := range rsp.FaceShape {
+		rs[i] = s.Bounds()
+	}
+	return rs
+}
+
+//Rects 所有人脸框
+func (rsp MultiFaceIdentifyRsp) Rects() []Rect {
+	rs := make([]Rect, len(rsp.Results))
+	for i, r := range rsp.Results {
+		rs[i] = r.FaceRect.Rect()
+	}
+	return rs
+}
+
+//Rect 人脸框
+func (rsp GetFaceInfoRsp) Rect() Rect {
+	return rsp.FaceInfo.Rect()
+}
+
+//CarRect 车辆框
+func (rsp CarClassifyRsp) CarRect() Rect {
+	return rsp.CarCoord.Rect()
+}
+
+//LogoRects 车标框
+func (rsp CarClassifyRsp) LogoRects() []Rect {
+	rs := make([]Rect, len(rsp.LogoCoord))
+	for i, c := range rsp.LogoCoord {
+		rs[i] = c.Rect()
+	}
+	return rs
+}
+
+func itemRects(items []ItemContent) []Rect {
+	rs := make([]Rect, len(items))
+	for i, it := range items {
+		rs[i] = it.Rect()
+	}
+	return rs
+}
+
+//Rects 所有文字框
+func (rsp GeneralOcrRsp) Rects() []Rect {
+	return itemRects(rsp.Items)
+}
+
+//Rects 所有文字框
+func (rsp DriverlicenseOcrRsp) Rects() []Rect {
+	return itemRects(rsp.Items)
+}
+
+//Rects 所有文字框
+func (rsp BCOcrRsp) Rects() []Rect {
+	return itemRects(rsp.Items)
+}
+
+//MapPoint 变换点
+func (t Transform) MapPoint(p Point) Point {
+	x, y := t.Apply(p.X, p.Y)
+	return Point{x, y}
+}
+
+//MapRect 变换矩形, 返回四个角变换后的外接矩形
+func (t Transform) MapRect(r Rect) Rect {
+	x, y, w, h := t.ApplyRect(r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	return RectXYWH(x, y, w, h)
+}
+
+//MapPolygon 变换多边形的每个顶点, 旋转后的矩形用它保留形状
+func (t Transform) MapPolygon(p Polygon) Polygon {
+	out := make(Polygon, len(p))
+	for i, pt := range p {
+		out[i] = t.MapPoint(pt)
+	}
+	return out
+}
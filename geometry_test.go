@@ -0,0 +1,102 @@
+/*
+* File Name:	geometry_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"encoding/json"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestRectOps(t *testing.T) {
+	a := RectXYWH(0, 0, 10, 10)
+	b := RectXYWH(5, 5, 10, 10)
+	if iou := a.IoU(b); math.Abs(iou-25.0/175) > 1e-9 {
+		t.Errorf("IoU = %g, want %g\n", iou, 25.0/175)
+	}
+	if iou := a.IoU(RectXYWH(20, 20, 1, 1)); iou != 0 {
+		t.Errorf("IoU of disjoint rects = %g\n", iou)
+	}
+	if !a.Contains(Pt(0, 0)) || a.Contains(Pt(10, 5)) {
+		t.Errorf("Contains is not half-open\n")
+	}
+	if !a.Union(b).ContainsRect(b) || a.ContainsRect(b) {
+		t.Errorf("ContainsRect wrong\n")
+	}
+	if c := b.Clip(12, 8); c != RectXYWH(5, 5, 7, 3) {
+		t.Errorf("Clip = %+v\n", c)
+	}
+	if s := b.Scale(2, 0.5); s != RectXYWH(10, 2.5, 20, 5) {
+		t.Errorf("Scale = %+v\n", s)
+	}
+	if r := RectXYWH(1.4, 2.6, 3, 3).ImageRect(); r != image.Rect(1, 3, 4, 6) {
+		t.Errorf("ImageRect = %v\n", r)
+	}
+	if r := RectFrom(image.Rect(1, 2, 3, 4)); r != RectXYWH(1, 2, 2, 2) {
+		t.Errorf("RectFrom = %+v\n", r)
+	}
+}
+
+func TestPolygonOps(t *testing.T) {
+	//菱形
+	d := Polygon{{5, 0}, {10, 5}, {5, 10}, {0, 5}}
+	if a := d.Area(); a != 50 {
+		t.Errorf("Area = %g, want 50\n", a)
+	}
+	if !d.Contains(Pt(5, 5)) || d.Contains(Pt(1, 1)) {
+		t.Errorf("Contains wrong\n")
+	}
+	if b := d.Bounds(); b != RectXYWH(0, 0, 10, 10) {
+		t.Errorf("Bounds = %+v\n", b)
+	}
+	if a := d.Clip(5, 10).Area(); math.Abs(a-25) > 1e-9 {
+		t.Errorf("clipped area = %g, want 25\n", a)
+	}
+	sq := RectXYWH(0, 0, 10, 10).Polygon()
+	if iou := d.IoU(sq); math.Abs(iou-0.5) > 1e-9 {
+		t.Errorf("IoU = %g, want 0.5\n", iou)
+	}
+}
+
+func TestResponseGeometry(t *testing.T) {
+	var face DetectFaceRsp
+	json.Unmarshal([]byte(`{"image_width":100,"image_height":50,"face":[{"x":1,"y":2,"width":3.5,"height":4}]}`), &face)
+	if rs := face.Rects(); len(rs) != 1 || rs[0] != RectXYWH(1, 2, 3.5, 4) {
+		t.Errorf("DetectFaceRsp.Rects = %+v\n", rs)
+	}
+	if !face.Bounds().ContainsRect(face.Face[0].Rect()) {
+		t.Errorf("face outside image bounds\n")
+	}
+
+	var shape FaceShapeRsp
+	json.Unmarshal([]byte(`{"face_shape":[{"left_eye":[{"x":1,"y":1},{"x":3,"y":2}],"mouth":[{"x":2,"y":5}]}]}`), &shape)
+	if b := shape.Rects()[0]; b != RectXYWH(1, 1, 2, 4) {
+		t.Errorf("FaceShapeRsp.Rects = %+v\n", b)
+	}
+	if p := shape.FaceShape[0].Polygons()["left_eye"]; len(p) != 2 || p[1] != Pt(3, 2) {
+		t.Errorf("left_eye polygon = %v\n", p)
+	}
+
+	var car CarClassifyRsp
+	json.Unmarshal([]byte(`{"car_coord":{"x":1.5,"y":2,"width":10,"height":5},"logo_coord":[{"x":3,"y":3,"width":1,"height":1}]}`), &car)
+	if car.CarRect() != RectXYWH(1.5, 2, 10, 5) || car.LogoRects()[0] != RectXYWH(3, 3, 1, 1) {
+		t.Errorf("CarClassifyRsp rects = %+v %+v\n", car.CarRect(), car.LogoRects())
+	}
+
+	var ocr GeneralOcrRsp
+	json.Unmarshal([]byte(`{"items":[{"itemcoord":{"x":1,"y":2,"width":3,"height":4},"coords":[{"x":1,"y":2,"width":1,"height":4}]}]}`), &ocr)
+	if ocr.Rects()[0] != RectXYWH(1, 2, 3, 4) || ocr.Items[0].CharRects()[0] != RectXYWH(1, 2, 1, 4) {
+		t.Errorf("GeneralOcrRsp rects = %+v\n", ocr.Rects())
+	}
+
+	//旋转90度后用多边形保留形状
+	tr := OrientationTransform(6, 10, 20)
+	if r := tr.MapRect(RectXYWH(0, 0, 10, 20)); r != RectXYWH(0, 0, 20, 10) {
+		t.Errorf("MapRect = %+v\n", r)
+	}
+}
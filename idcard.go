@@ -0,0 +1,73 @@
+/*
+* File Name:	idcard.go
+* Description:  解码和保存身份证OCR返回的证件照片
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"io/ioutil"
+)
+
+var (
+	//ErrNoImage 返回中没有对应的照片
+	ErrNoImage = errors.New("image not present in response")
+)
+
+func decodeImageField(field string) ([]byte, error) {
+	if field == "" {
+		return nil, ErrNoImage
+	}
+	return base64.StdEncoding.DecodeString(field)
+}
+
+func decodeStdImage(data []byte, err error) (image.Image, error) {
+	if err != nil {
+		return nil, err
+	}
+	m, _, err := image.Decode(bytes.NewReader(data))
+	return m, err
+}
+
+func saveImageField(path, field string) error {
+	data, err := decodeImageField(field)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+//FrontImageData 返回FrontImage解码后的图片数据, 格式可用SniffImageFormat判断
+func (rsp IdcardOcrRsp) FrontImageData() ([]byte, error) {
+	return decodeImageField(rsp.FrontImage)
+}
+
+//DecodeFrontImage 把FrontImage解码为image.Image
+func (rsp IdcardOcrRsp) DecodeFrontImage() (image.Image, error) {
+	return decodeStdImage(rsp.FrontImageData())
+}
+
+//SaveFrontImage 把FrontImage原样保存到文件path, 身份证照片属于敏感信息, 新建文件的权限为0600
+func (rsp IdcardOcrRsp) SaveFrontImage(path string) error {
+	return saveImageField(path, rsp.FrontImage)
+}
+
+//BackImageData 返回BackImage解码后的图片数据, 格式可用SniffImageFormat判断
+func (rsp IdcardOcrRsp) BackImageData() ([]byte, error) {
+	return decodeImageField(rsp.BackImage)
+}
+
+//DecodeBackImage 把BackImage解码为image.Image
+func (rsp IdcardOcrRsp) DecodeBackImage() (image.Image, error) {
+	return decodeStdImage(rsp.BackImageData())
+}
+
+//SaveBackImage 同SaveFrontImage, 保存BackImage
+func (rsp IdcardOcrRsp) SaveBackImage(path string) error {
+	return saveImageField(path, rsp.BackImage)
+}
@@ -0,0 +1,256 @@
+/*
+* File Name:	image.go
+* Description:  请求图片的来源: 数据、URL、文件、io.Reader或image.Image
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+var (
+	//ErrNoImageEncoder ImageFromStd没有指定编码器
+	ErrNoImageEncoder = errors.New("image encoder is nil")
+)
+
+//Image 请求中的图片, 由ImageBytes, ImageURL, ImageFile, ImageReader或ImageFromStd创建.
+//文件和Reader在发送请求时才读取, 读取结果会被缓存, 同一个Image可以重复使用
+type Image struct {
+	url        string
+	load       func() ([]byte, error)
+	std        image.Image //ImageFromStd的原图, 预处理时直接使用, 不再解码
+	transform  *Transform
+	noBlurGate bool //不受SetBlurGate拦截, 用于校准
+}
+
+//ImageBytes 图片数据
+func ImageBytes(data []byte) Image {
+	return Image{load: func() ([]byte, error) { return data, nil }}
+}
+
+//ImageURL 图片的url, 由服务器下载
+func ImageURL(url string) Image {
+	return Image{url: url}
+}
+
+//onceLoader 只执行一次load并缓存结果
+func onceLoader(load func() ([]byte, error)) func() ([]byte, error) {
+	var (
+		once sync.Once
+		data []byte
+		err  error
+	)
+	return func() ([]byte, error) {
+		once.Do(func() { data, err = load() })
+		return data, err
+	}
+}
+
+//ImageFile 本地图片文件
+func ImageFile(path string) Image {
+	return Image{load: onceLoader(func() ([]byte, error) {
+		return ioutil.ReadFile(path)
+	})}
+}
+
+//ImageReader 从r读取图片数据, 直到io.EOF
+func ImageReader(r io.Reader) Image {
+	return Image{load: onceLoader(func() ([]byte, error) {
+		return ioutil.ReadAll(r)
+	})}
+}
+
+//ImageEncoder 将image.Image编码为图片数据
+type ImageEncoder func(w io.Writer, m image.Image) error
+
+//JPEGEncoder 按quality(1~100)编码为JPEG
+func JPEGEncoder(quality int) ImageEncoder {
+	return func(w io.Writer, m image.Image) error {
+		return jpeg.Encode(w, m, &jpeg.Options{Quality: quality})
+	}
+}
+
+//PNGEncoder 编码为PNG
+func PNGEncoder() ImageEncoder {
+	return png.Encode
+}
+
+//ImageFromStd 用enc编码标准库的image.Image, 如相机帧或缩略图.
+//开启预处理时需要缩小的图片不经过enc, 直接缩小后编码为JPEG
+func ImageFromStd(m image.Image, enc ImageEncoder) Image {
+	return Image{std: m, load: onceLoader(func() ([]byte, error) {
+		if enc == nil {
+			return nil, ErrNoImageEncoder
+		}
+		var buf bytes.Buffer
+		if err := enc(&buf, m); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	})}
+}
+
+//WithTransform 记录调用者已对图片做的变换, t把原图坐标映射到img的坐标.
+//SDK预处理的变换会接在t之后, 结果的Original方法据此映射回原图
+func (img Image) WithTransform(t Transform) Image {
+	img.transform = &t
+	return img
+}
+
+//ImageJPEG 按quality(1~100)把image.Image编码为JPEG
+func ImageJPEG(m image.Image, quality int) Image {
+	return ImageFromStd(m, JPEGEncoder(quality))
+}
+
+//ImagePNG 把image.Image编码为PNG
+func ImagePNG(m image.Image) Image {
+	return ImageFromStd(m, PNGEncoder())
+}
+
+//empty 未设置图片
+func (img Image) empty() bool {
+	return img.url == "" && img.load == nil
+}
+
+//IsURL 是否为URL图片
+func (img Image) IsURL() bool {
+	return img.url != ""
+}
+
+//URL 返回URL图片的url
+func (img Image) URL() string {
+	return img.url
+}
+
+//Bytes 返回图片数据, URL图片返回nil
+func (img Image) Bytes() ([]byte, error) {
+	if img.load == nil {
+		return nil, nil
+	}
+	return img.load()
+}
+
+//legacyImage 旧接口的imageType: 0代表图片数据, 其它代表url
+func legacyImage(image []byte, imageType int) Image {
+	if imageType == 0 {
+		return ImageBytes(image)
+	}
+	return ImageURL(string(image))
+}
+
+func legacyImages(images [][]byte, imageType int) []Image {
+	imgs := make([]Image, len(images))
+	for i, img := range images {
+		imgs[i] = legacyImage(img, imageType)
+	}
+	return imgs
+}
+
+//imageField 返回接口ifname请求中图片的base64数据或url, 二者只有一个非空
+func (y *Youtu) imageField(ifname string, img Image) (data, url string, err error) {
+	data, url, _, err = y.encodeImage(ifname, img)
+	return
+}
+
+//encodeImage 同imageField, 同时返回原图到发送图片的变换, 没有变换时为nil.
+//图片数据依次经过格式转换和预处理(如果开启), 再按接口限制校验, 不合法时返回ValidationError
+func (y *Youtu) encodeImage(ifname string, img Image) (data, url string, t *Transform, err error) {
+	t = img.transform
+	if img.IsURL() {
+		if err = withEndpoint(ifname, checkImageURL(img.url)); err != nil {
+			return
+		}
+		f := y.urlFetcher()
+		if f == nil || f.opts.Mode != URLFetch {
+			url = img.url
+			return
+		}
+		//URLFetch: SDK下载后按图片数据处理
+		u := img.url
+		img.url, img.load = "", func() ([]byte, error) { return f.fetch(u) }
+	}
+	var (
+		b      []byte
+		pt     = IdentityTransform()
+		limits = y.ImageLimitsFor(ifname)
+		p      = y.preprocessOptions()
+	)
+	switch {
+	case p != nil && img.std != nil:
+		if err = y.checkBlur(ifname, img, nil); err == nil {
+			b, pt, err = p.applyStd(ifname, limits, img.std, img.load)
+		}
+	default:
+		if b, err = img.Bytes(); err == nil && len(b) > 0 {
+			if err = y.checkBlur(ifname, img, b); err == nil {
+				b, err = y.normalize(ifname, b)
+			}
+		}
+		if err == nil && p != nil && len(b) > 0 {
+			b, pt, err = p.apply(ifname, limits, b)
+			err = withEndpoint(ifname, err)
+		}
+	}
+	if err != nil {
+		return
+	}
+	t = thenTransform(t, pt)
+	if err = withEndpoint(ifname, checkImageData(limits, b)); err != nil {
+		return
+	}
+	data = base64.StdEncoding.EncodeToString(b)
+	return
+}
+
+//decodeImage 把img解码为按EXIF方向摆正的RGBA, t是原图到返回图片的变换.
+//url图片只在设置了SDK下载(SetURLFetch)时可以解码
+func (y *Youtu) decodeImage(ifname string, img Image) (m *image.RGBA, t Transform, err error) {
+	t = IdentityTransform()
+	if img.transform != nil {
+		t = *img.transform
+	}
+	if img.std != nil {
+		return toRGBA(img.std), t, nil
+	}
+	if img.IsURL() {
+		if err = withEndpoint(ifname, checkImageURL(img.url)); err != nil {
+			return
+		}
+		f := y.urlFetcher()
+		if f == nil {
+			return nil, t, withEndpoint(ifname, invalid("Image", "url images need SetURLFetch to be decoded locally"))
+		}
+		u := img.url
+		img.url, img.load = "", func() ([]byte, error) { return f.fetch(u) }
+	}
+	data, err := img.Bytes()
+	if err != nil {
+		return
+	}
+	format, _, _ := inspectImage(data)
+	src, err := decodeAny(format, data)
+	if _, ok := err.(*ValidationError); ok {
+		return nil, t, withEndpoint(ifname, err)
+	}
+	if err != nil {
+		return nil, t, withEndpoint(ifname, invalid("Image", "cannot decode image: "+err.Error()))
+	}
+	m = toRGBA(src)
+	if format == FormatJPEG {
+		if o := exifOrientation(data); o > 1 && o <= 8 {
+			t = t.Then(OrientationTransform(o, m.Bounds().Dx(), m.Bounds().Dy()))
+			m = orient(m, o)
+		}
+	}
+	return
+}
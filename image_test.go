@@ -0,0 +1,214 @@
+/*
+* File Name:	image_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//testJPEG 生成w*h的JPEG图片数据
+func testJPEG(w, h int) []byte {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			m.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, m, nil)
+	return buf.Bytes()
+}
+
+//newEchoServer 返回一个记录请求体的测试服务器
+func newEchoServer(bodies *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		*bodies = append(*bodies, body)
+		w.Write([]byte(`{"errorcode":0}`))
+	}))
+}
+
+func TestImageSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "youtu-image")
+	if err != nil {
+		t.Errorf("TempDir failed: %s\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.jpg")
+	ioutil.WriteFile(path, []byte("file data"), 0644)
+
+	m := image.NewGray(image.Rect(0, 0, 2, 2))
+	m.Set(1, 1, color.White)
+
+	for _, c := range []struct {
+		img  Image
+		want string
+	}{
+		{ImageBytes([]byte("raw data")), "raw data"},
+		{ImageFile(path), "file data"},
+		{ImageReader(strings.NewReader("reader data")), "reader data"},
+	} {
+		//Reader只能读一次, 重复调用Bytes必须返回相同数据
+		for i := 0; i < 2; i++ {
+			b, err := c.img.Bytes()
+			if err != nil || string(b) != c.want {
+				t.Errorf("Bytes() = %q, %v, want %q\n", b, err, c.want)
+			}
+		}
+	}
+
+	b, err := ImageFromStd(m, PNGEncoder()).Bytes()
+	if err != nil {
+		t.Errorf("ImageFromStd failed: %s\n", err)
+		return
+	}
+	dm, err := png.Decode(bytes.NewReader(b))
+	if err != nil || dm.Bounds() != m.Bounds() {
+		t.Errorf("ImageFromStd produced %v, %v\n", dm, err)
+	}
+	if _, err := ImageFromStd(m, nil).Bytes(); err != ErrNoImageEncoder {
+		t.Errorf("ImageFromStd without encoder: err = %v\n", err)
+	}
+	if _, err := ImageFile(filepath.Join(dir, "missing.jpg")).Bytes(); err == nil {
+		t.Errorf("ImageFile of a missing file should fail\n")
+	}
+}
+
+func TestImageRequestFields(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := newEchoServer(&bodies)
+	defer srv.Close()
+	as, _ := NewAppSign(1, "id", "key", "user")
+	y := Init(as, srv.URL)
+
+	raw := testJPEG(64, 64)
+	b64 := base64.StdEncoding.EncodeToString(raw)
+	url := "http://example.com/a.jpg"
+
+	y.DetectFaceImage(ImageBytes(raw), false)
+	y.DetectFace([]byte(url), false, 1)
+	y.FaceCompareImage(ImageBytes(raw), ImageURL(url))
+	y.AddFaceImages("p", []Image{ImageBytes(raw), ImageURL(url)}, "")
+	if len(bodies) != 4 {
+		t.Errorf("got %d requests, want 4\n", len(bodies))
+		return
+	}
+	if bodies[0]["image"] != b64 || bodies[0]["url"] != nil {
+		t.Errorf("DetectFaceImage(ImageBytes) body = %v\n", bodies[0])
+	}
+	if bodies[1]["url"] != url || bodies[1]["image"] != nil {
+		t.Errorf("DetectFace(url, 1) body = %v\n", bodies[1])
+	}
+	if bodies[2]["imageA"] != b64 || bodies[2]["urlB"] != url {
+		t.Errorf("FaceCompareImage body = %v\n", bodies[2])
+	}
+	images, _ := bodies[3]["images"].([]interface{})
+	urls, _ := bodies[3]["urls"].([]interface{})
+	if len(images) != 1 || images[0] != b64 || len(urls) != 1 || urls[0] != url {
+		t.Errorf("AddFaceImages body = %v\n", bodies[3])
+	}
+
+	if _, err := y.GeneralOcrImage(ImageFile("/nonexistent/a.jpg"), ""); err == nil {
+		t.Errorf("GeneralOcrImage with unreadable file should fail\n")
+	}
+	if len(bodies) != 4 {
+		t.Errorf("request was sent although the image could not be read\n")
+	}
+}
+
+func TestImageStdPreprocess(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := newEchoServer(&bodies)
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	m := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	if _, err := y.DetectFaceImage(ImagePNG(m), false); err != nil {
+		t.Errorf("DetectFaceImage(ImagePNG) failed: %s\n", err)
+		return
+	}
+	data, _ := base64.StdEncoding.DecodeString(bodies[0]["image"].(string))
+	if SniffImageFormat(data) != FormatPNG {
+		t.Errorf("ImagePNG sent as %q\n", SniffImageFormat(data))
+	}
+
+	//需要缩小时直接缩放image.Image, 不使用编码器
+	y.SetPreprocess(&PreprocessOptions{DefaultMaxDimension: 100})
+	big := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	called := false
+	enc := func(w io.Writer, m image.Image) error {
+		called = true
+		return png.Encode(w, m)
+	}
+	rsp, err := y.DetectFaceImage(ImageFromStd(big, enc), false)
+	if err != nil {
+		t.Errorf("DetectFaceImage(ImageFromStd) failed: %s\n", err)
+		return
+	}
+	if called {
+		t.Errorf("encoder called although the image was downscaled\n")
+	}
+	data, _ = base64.StdEncoding.DecodeString(bodies[1]["image"].(string))
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != 100 || cfg.Height != 50 {
+		t.Errorf("preprocessed image %dx%d, %v, want 100x50\n", cfg.Width, cfg.Height, err)
+	}
+	if rsp.Transform == nil || rsp.Transform.Matrix()[0] != 0.25 {
+		t.Errorf("Transform = %v\n", rsp.Transform)
+	}
+}
+
+func TestIdcardOcrImages(t *testing.T) {
+	raw := testJPEG(30, 20)
+	rsp := IdcardOcrRsp{FrontImage: base64.StdEncoding.EncodeToString(raw)}
+	m, err := rsp.DecodeFrontImage()
+	if err != nil || m.Bounds().Dx() != 30 || m.Bounds().Dy() != 20 {
+		t.Errorf("DecodeFrontImage = %v, %v\n", m, err)
+	}
+	if _, err := rsp.DecodeBackImage(); err != ErrNoImage {
+		t.Errorf("DecodeBackImage without image: err = %v, want %v\n", err, ErrNoImage)
+	}
+
+	dir, err := ioutil.TempDir("", "youtu-idcard")
+	if err != nil {
+		t.Errorf("TempDir failed: %s\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "front.jpg")
+	if err := rsp.SaveFrontImage(path); err != nil {
+		t.Errorf("SaveFrontImage failed: %s\n", err)
+		return
+	}
+	if saved, _ := ioutil.ReadFile(path); !bytes.Equal(saved, raw) {
+		t.Errorf("saved front image differs from response\n")
+	}
+	if fi, err := os.Stat(path); err != nil {
+		t.Errorf("Stat saved front image failed: %s\n", err)
+	} else if fi.Mode().Perm() != 0600 {
+		t.Errorf("saved front image mode = %v, want 0600\n", fi.Mode().Perm())
+	}
+	if err := rsp.SaveBackImage(filepath.Join(dir, "back.jpg")); err != ErrNoImage {
+		t.Errorf("SaveBackImage without image: err = %v\n", err)
+	}
+}
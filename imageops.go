@@ -0,0 +1,173 @@
+/*
+* File Name:	imageops.go
+* Description:  纯Go的图片基本操作: 转换、缩放、旋转
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+//toRGBA 转换为原点在(0,0)的RGBA, 带透明度的像素与白色背景合成
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+//resize 缩放到w*h, 缩小时按面积平均, 放大时双线性插值
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if w == sw && h == sh {
+		return src
+	}
+	//先水平后垂直两遍, 中间结果用float32保存
+	tmp := make([]float32, w*sh*4)
+	for y := 0; y < sh; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+sw*4]
+		resampleLine(row, sw, tmp[y*w*4:], w)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	col := make([]float32, sh*4)
+	out := make([]float32, h*4)
+	for x := 0; x < w; x++ {
+		for y := 0; y < sh; y++ {
+			copy(col[y*4:y*4+4], tmp[(y*w+x)*4:])
+		}
+		resampleLineF(col, sh, out, h)
+		for y := 0; y < h; y++ {
+			for c := 0; c < 4; c++ {
+				dst.Pix[y*dst.Stride+x*4+c] = clampUint8(out[y*4+c])
+			}
+		}
+	}
+	return dst
+}
+
+func clampUint8(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+//resampleLine 将n个uint8像素(每像素4通道)重采样为m个float32像素
+func resampleLine(src []uint8, n int, dst []float32, m int) {
+	f := make([]float32, n*4)
+	for i, v := range src[:n*4] {
+		f[i] = float32(v)
+	}
+	resampleLineF(f, n, dst, m)
+}
+
+//resampleLineF 一维重采样, 每像素4通道
+func resampleLineF(src []float32, n int, dst []float32, m int) {
+	scale := float64(n) / float64(m)
+	if scale >= 1 {
+		//面积平均: 目标像素i覆盖源区间[i*scale, (i+1)*scale)
+		for i := 0; i < m; i++ {
+			start := float64(i) * scale
+			end := start + scale
+			var acc [4]float64
+			for j := int(start); j < n && float64(j) < end; j++ {
+				w := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+				for c := 0; c < 4; c++ {
+					acc[c] += w * float64(src[j*4+c])
+				}
+			}
+			for c := 0; c < 4; c++ {
+				dst[i*4+c] = float32(acc[c] / scale)
+			}
+		}
+		return
+	}
+	for i := 0; i < m; i++ {
+		x := (float64(i)+0.5)*scale - 0.5
+		if x < 0 {
+			x = 0
+		}
+		j := int(x)
+		if j >= n-1 {
+			j = n - 1
+		}
+		k := j + 1
+		if k > n-1 {
+			k = n - 1
+		}
+		t := float32(x - float64(j))
+		for c := 0; c < 4; c++ {
+			dst[i*4+c] = src[j*4+c]*(1-t) + src[k*4+c]*t
+		}
+	}
+}
+
+//fitSize 等比缩放使最长边不超过maxDim, 返回新尺寸
+func fitSize(w, h, maxDim int) (int, int) {
+	if maxDim <= 0 || (w <= maxDim && h <= maxDim) {
+		return w, h
+	}
+	if w >= h {
+		return maxDim, maxInt(1, int(math.Round(float64(h)*float64(maxDim)/float64(w))))
+	}
+	return maxInt(1, int(math.Round(float64(w)*float64(maxDim)/float64(h)))), maxDim
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+//orient 按EXIF方向值(1~8)变换图片, 使其以正确方向显示
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			var sx, sy int
+			switch orientation {
+			case 2: //水平翻转
+				sx, sy = w-1-dx, dy
+			case 3: //旋转180度
+				sx, sy = w-1-dx, h-1-dy
+			case 4: //垂直翻转
+				sx, sy = dx, h-1-dy
+			case 5: //沿主对角线翻转
+				sx, sy = dy, dx
+			case 6: //顺时针旋转90度
+				sx, sy = dy, h-1-dx
+			case 7: //沿副对角线翻转
+				sx, sy = w-1-dy, h-1-dx
+			case 8: //逆时针旋转90度
+				sx, sy = w-1-dy, dx
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[sy*src.Stride+sx*4:])
+		}
+	}
+	return dst
+}
@@ -8,7 +8,6 @@ package youtu
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -17,6 +16,21 @@ import (
 	"time"
 )
 
+//HTTPError 服务器返回了非200的HTTP状态码
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("httperrorcode: %d \n", e.StatusCode)
+}
+
+//apiStatus 各接口响应共有的状态字段
+type apiStatus struct {
+	ErrorCode int    `json:"errorcode"`
+	ErrorMsg  string `json:"errormsg"`
+}
+
 func (y *Youtu) interfaceURL(ifname string, urltype int) string {
 	if urltype == 3 {
 		return fmt.Sprintf("%s/youtu/carapi/%s", y.host, ifname)
@@ -32,7 +46,7 @@ func (y *Youtu) interfaceURL(ifname string, urltype int) string {
 func (y *Youtu) interfaceRequest(ifname string, req, rsp interface{}, urltype int) (err error) {
 	url := y.interfaceURL(ifname, urltype)
 	if y.debug {
-		fmt.Printf("req: %#v\n", req)
+		fmt.Printf("req: %s %T\n", ifname, req)
 	}
 	data, err := json.Marshal(req)
 	if err != nil {
@@ -40,6 +54,34 @@ func (y *Youtu) interfaceRequest(ifname string, req, rsp interface{}, urltype in
 	}
 	//fmt.Println(string(data))
 	body, err := y.get(url, string(data))
+	y.shared.record(y.tenant, err)
+	var st apiStatus
+	if err == nil {
+		json.Unmarshal(body, &st)
+	}
+	if y.signExpired(st.ErrorCode, err) {
+		//本地时钟偏差导致签名过期, get已根据Date头修正偏差, 重新签名重试一次
+		if y.debug {
+			fmt.Fprintf(os.Stderr, "sign expired, retry with clock skew %v\n", y.ClockSkew())
+		}
+		body, err = y.get(url, string(data))
+		y.shared.record(y.tenant, err)
+		st = apiStatus{}
+		if err == nil {
+			json.Unmarshal(body, &st)
+		}
+	}
+	if f := y.urlFetcher(); err == nil && f != nil && f.downloadFailed(st.ErrorCode) {
+		//服务器无法下载url图片, 由SDK下载后上传图片数据重试一次
+		fdata, ok, ferr := y.fetchFallback(f, ifname, data)
+		if ferr != nil {
+			return ferr
+		}
+		if ok {
+			body, err = y.get(url, string(fdata))
+			y.shared.record(y.tenant, err)
+		}
+	}
 	if err != nil {
 		return
 	}
@@ -55,35 +97,34 @@ func (y *Youtu) interfaceRequest(ifname string, req, rsp interface{}, urltype in
 }
 
 func (y *Youtu) get(addr string, req string) (rsp []byte, err error) {
-	client := &http.Client{
-		Timeout: time.Duration(5 * time.Second),
-	}
+	client := y.shared.client
 	httpreq, err := http.NewRequest("POST", addr, strings.NewReader(req))
 	if err != nil {
 		return
 	}
 	auth := y.sign()
 	if y.debug {
-		fmt.Fprintf(os.Stderr, "Authorization: %s\n", auth)
+		fmt.Fprintf(os.Stderr, "Authorization: %s (%d bytes)\n", redacted, len(auth))
 	}
 	httpreq.Header.Add("Authorization", auth)
 	httpreq.Header.Add("Content-Type", "text/json")
 	httpreq.Header.Add("User-Agent", "")
 	httpreq.Header.Add("Accept", "*/*")
 	//httpreq.Header.Add("Expect", "100-continue")
+	sent := time.Now()
 	resp, err := client.Do(httpreq)
 
 	if err != nil {
 		return
 	}
+	y.observeDate(sent, time.Now(), resp.Header)
 
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		errStr := fmt.Sprintf("httperrorcode: %d \n", resp.StatusCode)
-		err = errors.New(errStr)
+		err = &HTTPError{StatusCode: resp.StatusCode}
 		return
 	}
 
-	defer resp.Body.Close()
 	rsp, err = ioutil.ReadAll(resp.Body)
 	return
 }
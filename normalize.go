@@ -0,0 +1,260 @@
+/*
+* File Name:	normalize.go
+* Description:  上传前把服务不支持或容易误读的图片格式转换为基线JPEG
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+)
+
+//格式转换检测的问题
+const (
+	NormalizeWebP            = "webp"             //WebP, 需要注册解码器, 如导入golang.org/x/image/webp
+	NormalizeHEIF            = "heif"             //HEIC/AVIF, 需要注册解码器
+	NormalizeTIFF            = "tiff"             //TIFF, 只转换第一页
+	NormalizeBMP             = "bmp"              //BMP, 包括位域、RLE和OS/2格式
+	NormalizeAnimatedGIF     = "animated-gif"     //多帧GIF, 只转换第一帧
+	NormalizeCMYKJPEG        = "cmyk-jpeg"        //CMYK或YCCK的JPEG
+	NormalizeProgressiveJPEG = "progressive-jpeg" //渐进式JPEG
+	Normalize16BitPNG        = "16bit-png"        //每通道16位的PNG
+	NormalizeAlphaPNG        = "alpha-png"        //含透明像素的PNG, 与白色背景合成
+)
+
+//NormalizePolicy 接口的格式转换策略
+type NormalizePolicy struct {
+	Off     bool     //不转换, 也不报告
+	Keep    []string //不转换的问题, 如NormalizeProgressiveJPEG, 仍然报告
+	Quality int      //转换后的JPEG质量, 为0时使用DefaultNormalizeQuality
+}
+
+//DefaultNormalizeQuality 转换后JPEG的默认质量
+const DefaultNormalizeQuality = 92
+
+//NormalizeReport 一张图片的检测和转换结果
+type NormalizeReport struct {
+	Endpoint  string   //接口名
+	Format    string   //输入格式, 如"tiff"
+	Problems  []string //检测到的问题
+	Converted bool     //是否已转换为JPEG
+	Frames    int      //GIF的帧数或TIFF的页数
+	Width     int      //图片宽度
+	Height    int      //图片高度
+	InBytes   int      //输入大小
+	OutBytes  int      //发送的大小
+}
+
+//NormalizeOptions 格式转换选项, 由SetNormalize开启
+type NormalizeOptions struct {
+	Default   NormalizePolicy            //Endpoints中没有的接口使用的策略
+	Endpoints map[string]NormalizePolicy //各接口(如"generalocr")的策略
+	Report    func(NormalizeReport)      //每张检测到问题的图片调用一次, 可能被并发调用
+}
+
+//SetNormalize 开启格式转换, o为nil时关闭. 转换在预处理和校验之前进行,
+//只作用于图片数据和SDK下载的url图片, ImageFromStd的图片由调用者选择编码
+func (y *Youtu) SetNormalize(o *NormalizeOptions) {
+	var n *NormalizeOptions
+	if o != nil {
+		c := *o
+		c.Endpoints = make(map[string]NormalizePolicy, len(o.Endpoints))
+		for k, v := range o.Endpoints {
+			c.Endpoints[k] = v
+		}
+		n = &c
+	}
+	y.shared.mu.Lock()
+	y.shared.normalize = n
+	y.shared.mu.Unlock()
+}
+
+func (y *Youtu) normalizeOptions() *NormalizeOptions {
+	y.shared.mu.RLock()
+	defer y.shared.mu.RUnlock()
+	return y.shared.normalize
+}
+
+//normalize 按接口ifname的策略转换图片数据, 没有开启时原样返回
+func (y *Youtu) normalize(ifname string, data []byte) ([]byte, error) {
+	o := y.normalizeOptions()
+	if o == nil {
+		return data, nil
+	}
+	policy, ok := o.Endpoints[ifname]
+	if !ok {
+		policy = o.Default
+	}
+	if policy.Off {
+		return data, nil
+	}
+	out, report, err := normalizeImage(data, policy)
+	if err != nil {
+		return nil, withEndpoint(ifname, err)
+	}
+	if len(report.Problems) > 0 && o.Report != nil {
+		report.Endpoint = ifname
+		o.Report(report)
+	}
+	return out, nil
+}
+
+//inspectImage 识别输入格式和问题
+func inspectImage(data []byte) (format string, problems []string, frames int) {
+	switch format = SniffImageFormat(data); format {
+	case FormatJPEG:
+		segs, _, _ := jpegSegments(data)
+		for _, s := range segs {
+			m := s.marker
+			if m < 0xc0 || m > 0xcf || m == 0xc4 || m == 0xc8 || m == 0xcc {
+				continue
+			}
+			//SOF: 精度(1), 高(2), 宽(2), 分量数(1)
+			if p := data[s.start+4 : s.end]; len(p) >= 6 && p[5] == 4 {
+				problems = append(problems, NormalizeCMYKJPEG)
+			}
+			if m == 0xc2 || m == 0xc6 || m == 0xca || m == 0xce {
+				problems = append(problems, NormalizeProgressiveJPEG)
+			}
+			break
+		}
+	case FormatPNG:
+		//IHDR: 位深在偏移24, 颜色类型在偏移25
+		if len(data) >= 26 {
+			if data[24] == 16 {
+				problems = append(problems, Normalize16BitPNG)
+			}
+			if data[25] == 4 || data[25] == 6 || bytes.Contains(data, []byte("tRNS")) {
+				problems = append(problems, NormalizeAlphaPNG)
+			}
+		}
+	case FormatGIF:
+		if checkDecodeSize(data) != nil {
+			break
+		}
+		if g, err := gif.DecodeAll(bytes.NewReader(data)); err == nil {
+			if frames = len(g.Image); frames > 1 {
+				problems = append(problems, NormalizeAnimatedGIF)
+			}
+		}
+	case FormatBMP:
+		problems = append(problems, NormalizeBMP)
+	default:
+		switch {
+		case isTIFF(data):
+			format, frames = NormalizeTIFF, tiffPageCount(data)
+		case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+			format = NormalizeWebP
+		case len(data) >= 12 && string(data[4:8]) == "ftyp" && isHEIFBrand(string(data[8:12])):
+			format = NormalizeHEIF
+		default:
+			return
+		}
+		problems = append(problems, format)
+	}
+	return
+}
+
+func isHEIFBrand(brand string) bool {
+	switch brand {
+	case "heic", "heix", "hevc", "heim", "heis", "mif1", "msf1", "avif", "avis":
+		return true
+	}
+	return false
+}
+
+//decodeAny 解码各种输入格式, WebP和HEIF使用已注册的解码器
+func decodeAny(format string, data []byte) (image.Image, error) {
+	switch format {
+	case FormatBMP:
+		return decodeBMP(data)
+	case NormalizeTIFF:
+		return decodeTIFF(data)
+	}
+	if err := checkDecodeSize(data); err != nil {
+		return nil, err
+	}
+	m, _, err := image.Decode(bytes.NewReader(data))
+	if err == image.ErrFormat && (format == NormalizeWebP || format == NormalizeHEIF) {
+		return nil, fmt.Errorf("no %s decoder registered, import one (e.g. golang.org/x/image/webp) to enable conversion", format)
+	}
+	return m, err
+}
+
+//opaque 图片是否没有透明像素
+func opaque(m image.Image) bool {
+	if o, ok := m.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := m.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+//needConvert 是否有policy不保留的问题
+func needConvert(problems []string, policy NormalizePolicy) bool {
+	for _, p := range problems {
+		if !contains(policy.Keep, p) {
+			return true
+		}
+	}
+	return false
+}
+
+//normalizeImage 按policy把有问题的图片转换为基线JPEG
+func normalizeImage(data []byte, policy NormalizePolicy) (out []byte, report NormalizeReport, err error) {
+	format, problems, frames := inspectImage(data)
+	report = NormalizeReport{Format: format, Problems: problems, Frames: frames, InBytes: len(data), OutBytes: len(data)}
+	if !needConvert(problems, policy) {
+		return data, report, nil
+	}
+	var m image.Image
+	if m, err = decodeAny(format, data); err != nil {
+		if _, ok := err.(*ValidationError); !ok {
+			err = invalid("Image", fmt.Sprintf("cannot convert %s image: %v", format, err))
+		}
+		return nil, report, err
+	}
+	report.Width, report.Height = m.Bounds().Dx(), m.Bounds().Dy()
+	//声明了透明度但实际不透明的PNG不需要转换
+	if contains(problems, NormalizeAlphaPNG) && opaque(m) {
+		kept := problems[:0]
+		for _, p := range problems {
+			if p != NormalizeAlphaPNG {
+				kept = append(kept, p)
+			}
+		}
+		report.Problems = kept
+		if !needConvert(kept, policy) {
+			return data, report, nil
+		}
+	}
+	q := policy.Quality
+	if q <= 0 || q > 100 {
+		q = DefaultNormalizeQuality
+	}
+	if out, err = encodeJPEG(toRGBA(m), q); err != nil {
+		return nil, report, err
+	}
+	report.Converted, report.OutBytes = true, len(out)
+	return out, report, nil
+}
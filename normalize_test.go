@@ -0,0 +1,367 @@
+/*
+* File Name:	normalize_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"compress/lzw"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+//testPixel 测试图片(x, y)处的颜色
+func testPixel(x, y int) color.RGBA {
+	return color.RGBA{uint8(x * 40), uint8(y * 60), uint8(200 - x*10), 0xff}
+}
+
+//bmpFile 拼接BMP文件头、信息头、附加数据(位域或调色板)和像素数据
+func bmpFile(w, h int32, bpp uint16, compression uint32, extra, pix []byte) []byte {
+	le := binary.LittleEndian
+	b := make([]byte, 54)
+	copy(b, "BM")
+	le.PutUint32(b[10:], uint32(54+len(extra)))
+	le.PutUint32(b[14:], 40)
+	le.PutUint32(b[18:], uint32(w))
+	le.PutUint32(b[22:], uint32(h))
+	le.PutUint16(b[26:], 1)
+	le.PutUint16(b[28:], bpp)
+	le.PutUint32(b[30:], compression)
+	b = append(append(b, extra...), pix...)
+	le.PutUint32(b[2:], uint32(len(b)))
+	return b
+}
+
+//testBMPs 生成4*3的各种BMP, 像素为testPixel或调色板索引
+func testBMPs() map[string][]byte {
+	const w, h = 4, 3
+	bmps := map[string][]byte{}
+
+	//24位, 自下而上, 每行补齐到4字节
+	var pix []byte
+	for y := h - 1; y >= 0; y-- {
+		for x := 0; x < w; x++ {
+			c := testPixel(x, y)
+			pix = append(pix, c.B, c.G, c.R)
+		}
+	}
+	bmps["24bit"] = bmpFile(w, h, 24, 0, nil, pix)
+
+	//32位BI_BITFIELDS, 自上而下
+	masks := make([]byte, 12)
+	binary.LittleEndian.PutUint32(masks[0:], 0x0000ff00)
+	binary.LittleEndian.PutUint32(masks[4:], 0x00ff0000)
+	binary.LittleEndian.PutUint32(masks[8:], 0xff000000)
+	pix = nil
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := testPixel(x, y)
+			pix = append(pix, 0, c.R, c.G, c.B)
+		}
+	}
+	bmps["bitfields"] = bmpFile(w, -h, 32, 3, masks, pix)
+
+	//8位调色板, 索引为x
+	var pal []byte
+	for i := 0; i < 256; i++ {
+		c := testPixel(i%w, 0)
+		pal = append(pal, c.B, c.G, c.R, 0)
+	}
+	pix = nil
+	for y := 0; y < h; y++ {
+		pix = append(pix, 0, 1, 2, 3)
+	}
+	bmps["palette"] = bmpFile(w, h, 8, 0, pal, pix)
+
+	//RLE8: 重复段和原样段各一
+	var rle []byte
+	for y := 0; y < h; y++ {
+		rle = append(rle, 1, 0, 0, 3, 1, 2, 3, 0, 0, 0)
+	}
+	rle = append(rle, 0, 1)
+	bmps["rle8"] = bmpFile(w, h, 8, 1, pal, rle)
+	return bmps
+}
+
+func TestDecodeBMP(t *testing.T) {
+	for name, data := range testBMPs() {
+		m, err := decodeBMP(data)
+		if err != nil {
+			t.Errorf("decodeBMP(%s) failed: %s\n", name, err)
+			continue
+		}
+		if m.Bounds() != image.Rect(0, 0, 4, 3) {
+			t.Errorf("decodeBMP(%s) bounds = %v\n", name, m.Bounds())
+			continue
+		}
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 4; x++ {
+				want := testPixel(x, y)
+				if name == "palette" || name == "rle8" {
+					want = testPixel(x, 0)
+				}
+				if got := color.RGBAModel.Convert(m.At(x, y)); got != want {
+					t.Errorf("decodeBMP(%s) (%d, %d) = %v, want %v\n", name, x, y, got, want)
+				}
+			}
+		}
+	}
+	if _, err := decodeBMP(testBMP(4, 3)); err == nil {
+		t.Errorf("decodeBMP accepted truncated pixel data\n")
+	}
+
+	//几百字节的RLE8文件声明32768x32768
+	huge := bmpFile(1<<15, 1<<15, 8, 1, make([]byte, 4*256), []byte{0, 1})
+	if _, err := decodeBMP(huge); err != errBMPFormat {
+		t.Errorf("decodeBMP(huge rle8) = %v, want %v\n", err, errBMPFormat)
+	}
+	//24位文件的像素数据不足声明的尺寸
+	short := bmpFile(1<<13, 1<<13, 24, 0, nil, make([]byte, 64))
+	if _, err := decodeBMP(short); err != errBMPFormat {
+		t.Errorf("decodeBMP(short 24bit) = %v, want %v\n", err, errBMPFormat)
+	}
+}
+
+//tiffPackBits 按PackBits原样段编码
+func tiffPackBits(src []byte) []byte {
+	var out []byte
+	for len(src) > 0 {
+		n := minInt(len(src), 128)
+		out = append(append(out, byte(n-1)), src[:n]...)
+		src = src[n:]
+	}
+	return out
+}
+
+//testTIFF 生成w*h的8位RGB多页TIFF, 每页一个条带, 第i页的像素为testPixel加i
+func testTIFF(w, h, pages int, compression uint16, predictor bool) []byte {
+	le := binary.LittleEndian
+	buf := []byte("II*\x00\x00\x00\x00\x00")
+	next := 4
+	for p := 0; p < pages; p++ {
+		var strip []byte
+		for y := 0; y < h; y++ {
+			row := make([]byte, 0, w*3)
+			for x := 0; x < w; x++ {
+				c := testPixel(x, y)
+				row = append(row, c.R+uint8(p), c.G, c.B)
+			}
+			if predictor {
+				for i := len(row) - 1; i >= 3; i-- {
+					row[i] -= row[i-3]
+				}
+			}
+			strip = append(strip, row...)
+		}
+		switch compression {
+		case 5:
+			var b bytes.Buffer
+			zw := lzw.NewWriter(&b, lzw.MSB, 8)
+			zw.Write(strip)
+			zw.Close()
+			strip = b.Bytes()
+		case 8:
+			var b bytes.Buffer
+			zw := zlib.NewWriter(&b)
+			zw.Write(strip)
+			zw.Close()
+			strip = b.Bytes()
+		case 32773:
+			strip = tiffPackBits(strip)
+		}
+		stripOffset := len(buf)
+		buf = append(buf, strip...)
+		if len(buf)%2 == 1 {
+			buf = append(buf, 0)
+		}
+
+		pred := uint32(1)
+		if predictor {
+			pred = 2
+		}
+		entries := [][3]uint32{
+			{tiffImageWidth, 4, uint32(w)},
+			{tiffImageLength, 4, uint32(h)},
+			{tiffBitsPerSample, 3, 8},
+			{tiffCompression, 3, uint32(compression)},
+			{tiffPhotometric, 3, 2},
+			{tiffStripOffsets, 4, uint32(stripOffset)},
+			{tiffSamplesPerPixel, 3, 3},
+			{tiffRowsPerStrip, 4, uint32(h)},
+			{tiffStripByteCounts, 4, uint32(len(strip))},
+			{tiffPredictor, 3, pred},
+		}
+		le.PutUint32(buf[next:], uint32(len(buf)))
+		ifd := make([]byte, 2+12*len(entries)+4)
+		le.PutUint16(ifd, uint16(len(entries)))
+		for i, e := range entries {
+			d := ifd[2+12*i:]
+			le.PutUint16(d, uint16(e[0]))
+			le.PutUint16(d[2:], uint16(e[1]))
+			le.PutUint32(d[4:], 1)
+			if e[1] == 3 {
+				le.PutUint16(d[8:], uint16(e[2]))
+			} else {
+				le.PutUint32(d[8:], e[2])
+			}
+		}
+		next = len(buf) + len(ifd) - 4
+		buf = append(buf, ifd...)
+	}
+	return buf
+}
+
+func TestDecodeTIFF(t *testing.T) {
+	for _, c := range []struct {
+		compression uint16
+		predictor   bool
+	}{
+		{1, false}, {32773, false}, {5, false}, {8, true},
+	} {
+		data := testTIFF(4, 3, 2, c.compression, c.predictor)
+		if n := tiffPageCount(data); n != 2 {
+			t.Errorf("tiffPageCount(compression %d) = %d\n", c.compression, n)
+		}
+		pages, err := decodeTIFFPages(data)
+		if err != nil || len(pages) != 2 {
+			t.Errorf("decodeTIFFPages(compression %d) = %d pages, %v\n", c.compression, len(pages), err)
+			continue
+		}
+		for p, m := range pages {
+			for y := 0; y < 3; y++ {
+				for x := 0; x < 4; x++ {
+					want := testPixel(x, y)
+					want.R += uint8(p)
+					if got := color.RGBAModel.Convert(m.At(x, y)); got != want {
+						t.Errorf("page %d (compression %d) (%d, %d) = %v, want %v\n", p, c.compression, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+	if _, err := decodeTIFF([]byte("II*\x00\xff\xff\x00\x00")); err == nil {
+		t.Errorf("decodeTIFF accepted a bad IFD offset\n")
+	}
+}
+
+func TestNormalizeImage(t *testing.T) {
+	var pngRGBA, pngAlpha, png16, animated bytes.Buffer
+	m := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := range m.Pix {
+		m.Pix[i] = 0xff
+	}
+	png.Encode(&pngRGBA, m)
+	m.Pix[3] = 0
+	png.Encode(&pngAlpha, m)
+	png.Encode(&png16, image.NewGray16(image.Rect(0, 0, 8, 8)))
+	pal := []color.Color{color.Black, color.White}
+	gif.EncodeAll(&animated, &gif.GIF{
+		Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 8, 8), pal), image.NewPaletted(image.Rect(0, 0, 8, 8), pal)},
+		Delay: []int{10, 10},
+	})
+	//把SOF0改为SOF2, 只用于检测
+	progressive := testJPEG(8, 8)
+	if i := bytes.Index(progressive, []byte{0xff, 0xc0}); i > 0 {
+		progressive[i+1] = 0xc2
+	}
+
+	for _, c := range []struct {
+		name      string
+		data      []byte
+		problems  string
+		converted bool
+		frames    int
+	}{
+		{"jpeg", testJPEG(8, 8), "", false, 0},
+		{"opaque rgba png", pngRGBA.Bytes(), "", false, 0},
+		{"alpha png", pngAlpha.Bytes(), NormalizeAlphaPNG, true, 0},
+		{"16bit png", png16.Bytes(), Normalize16BitPNG, true, 0},
+		{"animated gif", animated.Bytes(), NormalizeAnimatedGIF, true, 2},
+		{"bmp", testBMPs()["24bit"], NormalizeBMP, true, 0},
+		{"tiff", testTIFF(4, 3, 3, 1, false), NormalizeTIFF, true, 3},
+		{"progressive jpeg", progressive, NormalizeProgressiveJPEG, false, 0},
+	} {
+		out, r, err := normalizeImage(c.data, NormalizePolicy{Keep: []string{NormalizeProgressiveJPEG}})
+		if err != nil {
+			t.Errorf("normalizeImage(%s) failed: %s\n", c.name, err)
+			continue
+		}
+		if strings.Join(r.Problems, ",") != c.problems || r.Converted != c.converted || r.Frames != c.frames {
+			t.Errorf("normalizeImage(%s) report = %+v\n", c.name, r)
+			continue
+		}
+		if !c.converted {
+			if !bytes.Equal(out, c.data) {
+				t.Errorf("normalizeImage(%s) changed kept data\n", c.name)
+			}
+			continue
+		}
+		if SniffImageFormat(out) != FormatJPEG || r.OutBytes != len(out) {
+			t.Errorf("normalizeImage(%s) output is not JPEG\n", c.name)
+		}
+	}
+
+	//透明像素与白色背景合成
+	out, _, _ := normalizeImage(pngAlpha.Bytes(), NormalizePolicy{})
+	j, _, err := image.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Errorf("decode converted png failed: %s\n", err)
+		return
+	}
+	if r, g, b, _ := j.At(0, 0).RGBA(); r>>8 < 0xf0 || g>>8 < 0xf0 || b>>8 < 0xf0 {
+		t.Errorf("transparent pixel not composited onto white: %v\n", j.At(0, 0))
+	}
+
+	//没有注册WebP解码器时给出校验错误
+	webp := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), make([]byte, 16)...)
+	var ve *ValidationError
+	if _, _, err := normalizeImage(webp, NormalizePolicy{}); !errors.As(err, &ve) || !strings.Contains(err.Error(), "no webp decoder") {
+		t.Errorf("normalizeImage(webp) = %v\n", err)
+	}
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := newEchoServer(&bodies)
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+	var reports []NormalizeReport
+	y.SetNormalize(&NormalizeOptions{
+		Endpoints: map[string]NormalizePolicy{"faceshape": {Off: true}},
+		Report:    func(r NormalizeReport) { reports = append(reports, r) },
+	})
+
+	tiff := testTIFF(64, 64, 1, 8, true)
+	if _, err := y.DetectFaceImage(ImageBytes(tiff), false); err != nil {
+		t.Errorf("DetectFaceImage(tiff) failed: %s\n", err)
+		return
+	}
+	sent, _ := base64.StdEncoding.DecodeString(bodies[0]["image"].(string))
+	if SniffImageFormat(sent) != FormatJPEG {
+		t.Errorf("tiff not converted to jpeg before upload\n")
+	}
+	if len(reports) != 1 || reports[0].Endpoint != "detectface" || !reports[0].Converted || reports[0].Width != 64 {
+		t.Errorf("reports = %+v\n", reports)
+	}
+
+	//faceshape关闭转换, TIFF原样交给校验
+	if _, err := y.FaceShapeImage(ImageBytes(tiff), false); err == nil || len(reports) != 1 {
+		t.Errorf("FaceShapeImage(tiff) with normalize off = %v, %d reports\n", err, len(reports))
+	}
+
+	y.SetNormalize(nil)
+	if _, err := y.DetectFaceImage(ImageBytes(tiff), false); err == nil {
+		t.Errorf("DetectFaceImage(tiff) accepted without normalize\n")
+	}
+}
@@ -0,0 +1,371 @@
+/*
+* File Name:	pool.go
+* Description:  多AppID负载分担, 只读接口在多个租户间加权轮询
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+//DefaultThrottleCooldown 被限流的应用移出轮询的默认时长
+const DefaultThrottleCooldown = 30 * time.Second
+
+var (
+	//ErrPoolEmpty 池中没有成员
+	ErrPoolEmpty = errors.New("pool: no members")
+	//ErrPoolThrottled 池中所有成员都处于限流冷却中
+	ErrPoolThrottled = errors.New("pool: all members are throttled")
+	//ErrGroupNotPinned 组没有绑定到池中的应用
+	ErrGroupNotPinned = errors.New("pool: group is not pinned to any member")
+)
+
+//PoolMember 池成员, Tenant为客户端上已注册的租户名
+type PoolMember struct {
+	Tenant string
+	Weight int //权重, 小于等于0按1计
+}
+
+//PoolMemberStatus 池成员状态
+type PoolMemberStatus struct {
+	Tenant         string
+	Weight         int
+	Requests       uint64    //经池发出的请求数
+	Throttles      uint64    //被限流的次数
+	ThrottledUntil time.Time //冷却结束时间, 零值表示未被限流
+}
+
+type poolMember struct {
+	yt      *Youtu
+	weight  int
+	current int //平滑加权轮询的当前权重
+	status  PoolMemberStatus
+}
+
+//Pool 在多个AppSign之间分担只读请求(检测、图片识别、OCR)的客户端.
+//被限流的应用在冷却期内移出轮询; 人脸库接口必须通过ForGroup
+//使用拥有该组的应用, 不参与轮询
+type Pool struct {
+	//Cooldown 被限流后移出轮询的时长, 为0时使用DefaultThrottleCooldown
+	Cooldown time.Duration
+	//ThrottleErrorCodes 视为限流的errorcode. HTTP 429总是视为限流
+	ThrottleErrorCodes []int
+
+	mu      sync.Mutex
+	members []*poolMember
+	groups  map[string]*poolMember
+	now     func() time.Time
+}
+
+//NewPool 用y上已注册的租户创建池, 成员共享y的连接
+func NewPool(y *Youtu, members ...PoolMember) (*Pool, error) {
+	if len(members) == 0 {
+		return nil, ErrPoolEmpty
+	}
+	p := &Pool{
+		groups: make(map[string]*poolMember),
+		now:    time.Now,
+	}
+	for _, m := range members {
+		yt, err := y.Tenant(m.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		w := m.Weight
+		if w <= 0 {
+			w = 1
+		}
+		p.members = append(p.members, &poolMember{
+			yt:     yt,
+			weight: w,
+			status: PoolMemberStatus{Tenant: m.Tenant, Weight: w},
+		})
+	}
+	return p, nil
+}
+
+//next 平滑加权轮询选出一个未被限流的成员
+func (p *Pool) next() (*poolMember, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var best *poolMember
+	total := 0
+	for _, m := range p.members {
+		if now.Before(m.status.ThrottledUntil) {
+			continue
+		}
+		m.current += m.weight
+		total += m.weight
+		if best == nil || m.current > best.current {
+			best = m
+		}
+	}
+	if best == nil {
+		return nil, ErrPoolThrottled
+	}
+	best.current -= total
+	best.status.Requests++
+	return best, nil
+}
+
+func (p *Pool) throttled(errorCode int, err error) bool {
+	if he, ok := err.(*HTTPError); ok {
+		return he.StatusCode == 429
+	}
+	if err != nil {
+		return false
+	}
+	for _, c := range p.ThrottleErrorCodes {
+		if c == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pool) throttle(m *poolMember) {
+	cooldown := p.Cooldown
+	if cooldown == 0 {
+		cooldown = DefaultThrottleCooldown
+	}
+	p.mu.Lock()
+	m.status.Throttles++
+	m.current = 0
+	m.status.ThrottledUntil = p.now().Add(cooldown)
+	p.mu.Unlock()
+}
+
+//Call 选择一个成员执行fn, fn返回响应的errorcode和错误.
+//成员被限流时将其移出轮询并换下一个成员重试, 直到所有成员都被限流
+func (p *Pool) Call(fn func(y *Youtu) (errorCode int, err error)) error {
+	for {
+		m, err := p.next()
+		if err != nil {
+			return err
+		}
+		code, err := fn(m.yt)
+		if !p.throttled(code, err) {
+			return err
+		}
+		p.throttle(m)
+	}
+}
+
+//Members 返回各成员的状态
+func (p *Pool) Members() []PoolMemberStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := make([]PoolMemberStatus, len(p.members))
+	for i, m := range p.members {
+		status[i] = m.status
+	}
+	return status
+}
+
+//PinGroup 将组绑定到拥有它的租户
+func (p *Pool) PinGroup(groupID string, tenant string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, m := range p.members {
+		if m.status.Tenant == tenant {
+			p.groups[groupID] = m
+			return nil
+		}
+	}
+	return ErrTenantNotFound
+}
+
+//PinGroups 通过GetGroupIDs查询每个成员拥有的组并绑定, 任一成员查询失败时返回错误
+func (p *Pool) PinGroups() error {
+	for _, m := range p.members {
+		rsp, err := m.yt.GetGroupIDs()
+		if err != nil {
+			return err
+		}
+		if rsp.ErrorCode != 0 {
+			return rspError("getgroupids", int(rsp.ErrorCode), rsp.ErrorMsg)
+		}
+		p.mu.Lock()
+		for _, g := range rsp.GroupIDs {
+			p.groups[g] = m
+		}
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+//ForGroup 返回拥有groupID的应用的客户端, 用于FaceIdentify、NewPerson等人脸库接口
+func (p *Pool) ForGroup(groupID string) (*Youtu, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m, ok := p.groups[groupID]
+	if !ok {
+		return nil, ErrGroupNotPinned
+	}
+	return m.yt, nil
+}
+
+//DetectFace 见Youtu.DetectFaceImage
+func (p *Pool) DetectFace(image Image, isBigFace bool) (rsp DetectFaceRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.DetectFaceImage(image, isBigFace)
+		return rsp.ErrorCode, err
+	})
+	return
+}
+
+//FaceShape 见Youtu.FaceShapeImage
+func (p *Pool) FaceShape(image Image, isBigFace bool) (rsp FaceShapeRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.FaceShapeImage(image, isBigFace)
+		return rsp.ErrorCode, err
+	})
+	return
+}
+
+//FaceCompare 见Youtu.FaceCompareImage
+func (p *Pool) FaceCompare(imageA, imageB Image) (rsp FaceCompareRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.FaceCompareImage(imageA, imageB)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//FuzzyDetect 见Youtu.FuzzyDetectImage
+func (p *Pool) FuzzyDetect(image Image, seq string) (rsp FuzzyDetectRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.FuzzyDetectImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//FoodDetect 见Youtu.FoodDetectImage
+func (p *Pool) FoodDetect(image Image, seq string) (rsp FoodDetectRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.FoodDetectImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//ImageTag 见Youtu.ImageTagImage
+func (p *Pool) ImageTag(image Image, seq string) (rsp ImageTagRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.ImageTagImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//ImagePorn 见Youtu.ImagePornImage
+func (p *Pool) ImagePorn(image Image, seq string) (rsp ImagePornRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.ImagePornImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//ImageTerrorism 见Youtu.ImageTerrorismImage
+func (p *Pool) ImageTerrorism(image Image, seq string) (rsp ImagePornRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.ImageTerrorismImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//CarClassify 见Youtu.CarClassifyImage
+func (p *Pool) CarClassify(image Image, sessionID string) (rsp CarClassifyRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.CarClassifyImage(image, sessionID)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//IdcardOcr 见Youtu.IdcardOcrImage
+func (p *Pool) IdcardOcr(image Image, cardType int32, seq string) (rsp IdcardOcrRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.IdcardOcrImage(image, cardType, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//DriverLicenseOcr 见Youtu.DriverLicenseOcrImage
+func (p *Pool) DriverLicenseOcr(image Image, procType int32, seq string) (rsp DriverlicenseOcrRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.DriverLicenseOcrImage(image, procType, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//BCOcr 见Youtu.BCOcrImage
+func (p *Pool) BCOcr(image Image, seq string) (rsp BCOcrRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.BCOcrImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//GeneralOcr 见Youtu.GeneralOcrImage
+func (p *Pool) GeneralOcr(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.GeneralOcrImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//CreditCardOcr 见Youtu.CreditCardOcrImage
+func (p *Pool) CreditCardOcr(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.CreditCardOcrImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//BizLicenseOcr 见Youtu.BizLicenseOcrImage
+func (p *Pool) BizLicenseOcr(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.BizLicenseOcrImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
+
+//PlateOcr 见Youtu.PlateOcrImage
+func (p *Pool) PlateOcr(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	err = p.Call(func(y *Youtu) (int, error) {
+		var err error
+		rsp, err = y.PlateOcrImage(image, seq)
+		return int(rsp.ErrorCode), err
+	})
+	return
+}
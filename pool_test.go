@@ -0,0 +1,147 @@
+/*
+* File Name:	pool_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newPoolTestClient(url string) *Youtu {
+	def, _ := NewAppSign(1, "id1", "key1", "user1")
+	y := Init(def, url)
+	for i, name := range []string{"a", "b", "c"} {
+		as, _ := NewAppSign(uint32(i+2), "id", "key", "user")
+		y.AddTenant(name, as)
+	}
+	return y
+}
+
+func TestPoolWeightedRoundRobin(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AppID string `json:"app_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		hits[req.AppID]++
+		mu.Unlock()
+		json.NewEncoder(w).Encode(ImageTagRsp{})
+	}))
+	defer srv.Close()
+
+	p, err := NewPool(newPoolTestClient(srv.URL), PoolMember{"a", 2}, PoolMember{"b", 1})
+	if err != nil {
+		t.Errorf("NewPool failed: %s\n", err)
+		return
+	}
+	for i := 0; i < 30; i++ {
+		if _, err := p.ImageTag(ImageBytes(testJPEG(64, 64)), ""); err != nil {
+			t.Errorf("ImageTag failed: %s\n", err)
+			return
+		}
+	}
+	if hits["2"] != 20 || hits["3"] != 10 {
+		t.Errorf("hits = %v, want 20 on app 2 and 10 on app 3\n", hits)
+	}
+}
+
+func TestPoolThrottle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AppID string `json:"app_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.AppID {
+		case "2":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "3":
+			json.NewEncoder(w).Encode(GeneralOcrRsp{ErrorCode: -9999})
+		default:
+			json.NewEncoder(w).Encode(GeneralOcrRsp{SessionId: req.AppID})
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewPool(newPoolTestClient(srv.URL), PoolMember{Tenant: "a"}, PoolMember{Tenant: "b"}, PoolMember{Tenant: "c"})
+	if err != nil {
+		t.Errorf("NewPool failed: %s\n", err)
+		return
+	}
+	p.ThrottleErrorCodes = []int{-9999}
+	now := time.Unix(1500000000, 0)
+	p.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		rsp, err := p.GeneralOcr(ImageBytes(testJPEG(64, 64)), "")
+		if err != nil || rsp.SessionId != "4" {
+			t.Errorf("GeneralOcr = %v, %v, want response from app 4\n", rsp, err)
+			return
+		}
+	}
+	for _, st := range p.Members() {
+		throttled := st.Tenant != "c"
+		if throttled != now.Before(st.ThrottledUntil) {
+			t.Errorf("member %s throttled until %v\n", st.Tenant, st.ThrottledUntil)
+		}
+	}
+
+	now = now.Add(DefaultThrottleCooldown)
+	for i := 0; i < 3; i++ {
+		p.GeneralOcr(ImageBytes(testJPEG(64, 64)), "")
+	}
+	if st := p.Members(); st[0].Throttles != 2 {
+		t.Errorf("member a was not returned to rotation after cooldown: %+v\n", st[0])
+	}
+}
+
+func TestPoolForGroup(t *testing.T) {
+	//把app_id作为组id返回, 租户c(app_id为4)查询失败
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AppID string `json:"app_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.AppID == "4" {
+			json.NewEncoder(w).Encode(GetGroupIDsRsp{ErrorCode: -1101, ErrorMsg: "internal error"})
+			return
+		}
+		json.NewEncoder(w).Encode(GetGroupIDsRsp{GroupIDs: []string{req.AppID}})
+	}))
+	defer srv.Close()
+
+	p, _ := NewPool(newPoolTestClient(srv.URL), PoolMember{Tenant: "a"}, PoolMember{Tenant: "b"})
+	if _, err := p.ForGroup("2"); err != ErrGroupNotPinned {
+		t.Errorf("ForGroup before pinning: err = %v\n", err)
+	}
+	if err := p.PinGroups(); err != nil {
+		t.Errorf("PinGroups failed: %s\n", err)
+		return
+	}
+	for _, g := range []string{"2", "3"} {
+		y, err := p.ForGroup(g)
+		if err != nil || y.appID() != g {
+			t.Errorf("ForGroup(%s) = %v, %v\n", g, y, err)
+		}
+	}
+	if err := p.PinGroup("family", "missing"); err != ErrTenantNotFound {
+		t.Errorf("PinGroup to unknown tenant: err = %v\n", err)
+	}
+
+	//成员返回errorcode时报告错误, 而不是留下未绑定的组
+	p, _ = NewPool(newPoolTestClient(srv.URL), PoolMember{Tenant: "a"}, PoolMember{Tenant: "c"})
+	if err := p.PinGroups(); err == nil || !strings.Contains(err.Error(), "-1101") {
+		t.Errorf("PinGroups with a failing member: err = %v\n", err)
+	}
+}
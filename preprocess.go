@@ -0,0 +1,346 @@
+/*
+* File Name:	preprocess.go
+* Description:  上传前的图片预处理: EXIF方向、缩小、自适应JPEG质量和去除元数据
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"math"
+)
+
+const (
+	//DefaultPreprocessMaxDimension 未单独设置的接口, 预处理后图片的最长边
+	DefaultPreprocessMaxDimension = 1600
+	//DefaultPreprocessTargetBytes 预处理重新编码的目标大小
+	DefaultPreprocessTargetBytes = 500 << 10
+	//DefaultPreprocessMinQuality 自适应JPEG质量的下限
+	DefaultPreprocessMinQuality = 40
+	//DefaultPreprocessMaxQuality 自适应JPEG质量的上限
+	DefaultPreprocessMaxQuality = 90
+)
+
+//PreprocessOptions 上传前的图片预处理选项, 由SetPreprocess开启, 值为0的项使用默认值.
+//预处理只作用于图片数据, URL图片原样发送. 无法解码的图片原样交给校验
+type PreprocessOptions struct {
+	MaxDimension        map[string]int //各接口(如"generalocr")预处理后图片的最长边
+	DefaultMaxDimension int            //MaxDimension中没有的接口使用的最长边
+	TargetBytes         int            //重新编码的目标大小, 不超过接口的MaxBytes
+	MinQuality          int            //JPEG质量下限, 达不到TargetBytes时继续缩小图片
+	MaxQuality          int            //JPEG质量上限
+}
+
+//SetPreprocess 开启图片预处理, o为nil时关闭. 预处理按EXIF方向旋转图片,
+//缩小到接口的最长边, 以自适应质量重新编码为JPEG, 并去除EXIF(含GPS)等元数据
+func (y *Youtu) SetPreprocess(o *PreprocessOptions) {
+	var p *PreprocessOptions
+	if o != nil {
+		c := *o
+		c.MaxDimension = make(map[string]int, len(o.MaxDimension))
+		for k, v := range o.MaxDimension {
+			c.MaxDimension[k] = v
+		}
+		p = &c
+	}
+	y.shared.mu.Lock()
+	y.shared.preprocess = p
+	y.shared.mu.Unlock()
+}
+
+func (y *Youtu) preprocessOptions() *PreprocessOptions {
+	y.shared.mu.RLock()
+	defer y.shared.mu.RUnlock()
+	return y.shared.preprocess
+}
+
+func (o *PreprocessOptions) maxDimension(ifname string, l ImageLimits) int {
+	d, ok := o.MaxDimension[ifname]
+	if !ok {
+		d = o.DefaultMaxDimension
+	}
+	if d <= 0 {
+		d = DefaultPreprocessMaxDimension
+	}
+	if l.MaxWidth > 0 && d > l.MaxWidth {
+		d = l.MaxWidth
+	}
+	if l.MaxHeight > 0 && d > l.MaxHeight {
+		d = l.MaxHeight
+	}
+	return d
+}
+
+func (o *PreprocessOptions) quality() (min, max int) {
+	min, max = o.MinQuality, o.MaxQuality
+	if min <= 0 {
+		min = DefaultPreprocessMinQuality
+	}
+	if max <= 0 || max > 100 {
+		max = DefaultPreprocessMaxQuality
+	}
+	if min > max {
+		min = max
+	}
+	return
+}
+
+func (o *PreprocessOptions) target(l ImageLimits) int {
+	target := o.TargetBytes
+	if target <= 0 {
+		target = DefaultPreprocessTargetBytes
+	}
+	if l.MaxBytes > 0 && target > l.MaxBytes {
+		target = l.MaxBytes
+	}
+	return target
+}
+
+//apply 按接口ifname的设置预处理图片数据, 返回处理后的数据和原图到它的变换
+func (o *PreprocessOptions) apply(ifname string, l ImageLimits, data []byte) ([]byte, Transform, error) {
+	id := IdentityTransform()
+	format := SniffImageFormat(data)
+	if format != FormatJPEG && format != FormatPNG && format != FormatGIF {
+		return data, id, nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return data, id, nil
+	}
+	if err = checkDecodePixels(cfg.Width, cfg.Height); err != nil {
+		return data, id, err
+	}
+	orientation := 1
+	if format == FormatJPEG {
+		orientation = exifOrientation(data)
+	}
+	w, h := cfg.Width, cfg.Height
+	if orientation >= 5 {
+		w, h = h, w
+	}
+	target := o.target(l)
+	nw, nh := fitSize(w, h, o.maxDimension(ifname, l))
+
+	//不需要改动像素时只去除元数据, 避免重新编码损失画质
+	if orientation == 1 && nw == w && nh == h && len(data) <= target {
+		switch format {
+		case FormatJPEG:
+			return stripJPEGMetadata(data), id, nil
+		case FormatPNG:
+			return stripPNGMetadata(data), id, nil
+		}
+		return data, id, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, id, nil
+	}
+	return o.reencode(l, src, orientation, nw, nh, target)
+}
+
+//applyStd 预处理image.Image, 尺寸和大小都满足时使用encode的编码结果
+func (o *PreprocessOptions) applyStd(ifname string, l ImageLimits, m image.Image, encode func() ([]byte, error)) ([]byte, Transform, error) {
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	target := o.target(l)
+	nw, nh := fitSize(w, h, o.maxDimension(ifname, l))
+	if nw == w && nh == h {
+		data, err := encode()
+		if err != nil || len(data) <= target {
+			return data, IdentityTransform(), err
+		}
+	}
+	return o.reencode(l, m, 1, nw, nh, target)
+}
+
+//reencode 摆正src并缩放到nw*nh, 再以自适应质量编码为JPEG
+func (o *PreprocessOptions) reencode(l ImageLimits, src image.Image, orientation, nw, nh, target int) ([]byte, Transform, error) {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	m := resize(orient(toRGBA(src), orientation), nw, nh)
+	minQ, maxQ := o.quality()
+	out, fw, fh, err := encodeAdaptive(m, target, minQ, maxQ, l.MinWidth, l.MinHeight)
+	if err != nil {
+		return nil, IdentityTransform(), err
+	}
+	w, h := sw, sh
+	if orientation >= 5 {
+		w, h = sh, sw
+	}
+	t := OrientationTransform(orientation, sw, sh).
+		Then(ScaleTransform(float64(fw)/float64(w), float64(fh)/float64(h)))
+	return out, t, nil
+}
+
+func encodeJPEG(m image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, m, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//encodeAdaptive 选择不超过target的最高JPEG质量, 最低质量仍超过时缩小图片,
+//但不小于minW*minH. 返回编码结果和最终的宽高
+func encodeAdaptive(m *image.RGBA, target, minQ, maxQ, minW, minH int) ([]byte, int, int, error) {
+	for {
+		w, h := m.Bounds().Dx(), m.Bounds().Dy()
+		b, err := encodeJPEG(m, maxQ)
+		if err != nil || len(b) <= target {
+			return b, w, h, err
+		}
+		smallest, err := encodeJPEG(m, minQ)
+		if err != nil {
+			return nil, w, h, err
+		}
+		if len(smallest) <= target {
+			//二分查找满足大小的最高质量
+			best := smallest
+			lo, hi := minQ+1, maxQ-1
+			for lo <= hi {
+				q := (lo + hi) / 2
+				if b, err = encodeJPEG(m, q); err != nil {
+					return nil, w, h, err
+				}
+				if len(b) <= target {
+					best, lo = b, q+1
+				} else {
+					hi = q - 1
+				}
+			}
+			return best, w, h, nil
+		}
+		f := math.Min(0.9, math.Sqrt(float64(target)/float64(len(smallest)))*0.95)
+		nw, nh := int(float64(w)*f), int(float64(h)*f)
+		if nw < maxInt(minW, 1) || nh < maxInt(minH, 1) {
+			return smallest, w, h, nil
+		}
+		m = resize(m, nw, nh)
+	}
+}
+
+//jpegSegment JPEG中SOS之前的一个段, data[start:end]包含标记和长度
+type jpegSegment struct {
+	marker     byte
+	start, end int
+}
+
+//jpegSegments 返回SOS之前的段, 以及SOS的起始位置
+func jpegSegments(data []byte) (segs []jpegSegment, sos int, ok bool) {
+	if !bytes.HasPrefix(data, []byte{0xff, 0xd8}) {
+		return
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xff {
+			return
+		}
+		marker := data[i+1]
+		if marker == 0xff {
+			//填充字节
+			i++
+			continue
+		}
+		if marker == 0xda {
+			return segs, i, true
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return
+		}
+		segs = append(segs, jpegSegment{marker, i, i + 2 + n})
+		i += 2 + n
+	}
+	return
+}
+
+//exifOrientation 返回JPEG中EXIF的方向值, 没有时返回1
+func exifOrientation(data []byte) int {
+	segs, _, _ := jpegSegments(data)
+	for _, s := range segs {
+		p := data[s.start+4 : s.end]
+		if s.marker != 0xe1 || !bytes.HasPrefix(p, []byte("Exif\x00\x00")) {
+			continue
+		}
+		tiff := p[6:]
+		if len(tiff) < 8 {
+			return 1
+		}
+		var bo binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			bo = binary.LittleEndian
+		case "MM":
+			bo = binary.BigEndian
+		default:
+			return 1
+		}
+		ifd := int(bo.Uint32(tiff[4:]))
+		if ifd < 8 || ifd+2 > len(tiff) {
+			return 1
+		}
+		n := int(bo.Uint16(tiff[ifd:]))
+		for i := 0; i < n; i++ {
+			e := ifd + 2 + i*12
+			if e+12 > len(tiff) {
+				break
+			}
+			if bo.Uint16(tiff[e:]) == 0x0112 {
+				if o := int(bo.Uint16(tiff[e+8:])); o >= 1 && o <= 8 {
+					return o
+				}
+				return 1
+			}
+		}
+		return 1
+	}
+	return 1
+}
+
+//stripJPEGMetadata 去除EXIF/XMP(APP1)、IPTC等应用段和注释.
+//保留JFIF(APP0)、ICC颜色配置(APP2)和Adobe(APP14), 它们影响颜色解码
+func stripJPEGMetadata(data []byte) []byte {
+	segs, sos, ok := jpegSegments(data)
+	if !ok {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xff, 0xd8)
+	for _, s := range segs {
+		m := s.marker
+		if (m >= 0xe1 && m <= 0xef && m != 0xe2 && m != 0xee) || m == 0xfe {
+			continue
+		}
+		out = append(out, data[s.start:s.end]...)
+	}
+	return append(out, data[sos:]...)
+}
+
+//pngMetadataChunks 去除的PNG辅助块
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true,
+}
+
+//stripPNGMetadata 去除PNG中的EXIF和文本块
+func stripPNGMetadata(data []byte) []byte {
+	const sig = 8
+	out := append(make([]byte, 0, len(data)), data[:sig]...)
+	for i := sig; i < len(data); {
+		if i+12 > len(data) {
+			return data
+		}
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + n
+		if n < 0 || end > len(data) || end < i {
+			return data
+		}
+		if !pngMetadataChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out
+}
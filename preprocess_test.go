@@ -0,0 +1,209 @@
+/*
+* File Name:	preprocess_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+//withEXIF 在JPEG中插入带方向值和GPS标记的APP1段
+func withEXIF(data []byte, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a")
+	binary.Write(&tiff, binary.BigEndian, uint32(8))
+	binary.Write(&tiff, binary.BigEndian, uint16(2))
+	//Orientation, SHORT, 1
+	binary.Write(&tiff, binary.BigEndian, []uint16{0x0112, 3})
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientation, 0})
+	//GPSInfo, LONG, 1
+	binary.Write(&tiff, binary.BigEndian, []uint16{0x8825, 4})
+	binary.Write(&tiff, binary.BigEndian, []uint32{1, 0})
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPS 31.2304N 121.4737E")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	seg := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	out := append([]byte{0xff, 0xd8}, seg...)
+	out = append(out, payload...)
+	return append(out, data[2:]...)
+}
+
+func noiseJPEG(w, h int) []byte {
+	r := rand.New(rand.NewSource(1))
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	r.Read(m.Pix)
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, m, &jpeg.Options{Quality: 95})
+	return buf.Bytes()
+}
+
+func TestPreprocessOrientation(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 80, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 80; x++ {
+			src.Set(x, y, color.RGBA{uint8(x * 3), 0, 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, src, &jpeg.Options{Quality: 100})
+	data := withEXIF(buf.Bytes(), 6)
+	if o := exifOrientation(data); o != 6 {
+		t.Errorf("exifOrientation = %d, want 6\n", o)
+		return
+	}
+
+	o := &PreprocessOptions{}
+	out, _, err := o.apply("imagetag", DefaultImageLimits, data)
+	if err != nil {
+		t.Errorf("apply failed: %s\n", err)
+		return
+	}
+	if bytes.Contains(out, []byte("Exif")) || bytes.Contains(out, []byte("GPS")) {
+		t.Errorf("EXIF metadata not stripped\n")
+	}
+	m, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Errorf("Decode failed: %s\n", err)
+		return
+	}
+	if b := m.Bounds(); b.Dx() != 40 || b.Dy() != 80 {
+		t.Errorf("rotated size = %v, want 40x80\n", b.Size())
+		return
+	}
+	//顺时针旋转90度后, 原图左边(暗)在上, 右边(亮)在下
+	top, _, _, _ := m.At(20, 2).RGBA()
+	bottom, _, _, _ := m.At(20, 77).RGBA()
+	if top >= bottom {
+		t.Errorf("rotation direction wrong: top %d, bottom %d\n", top>>8, bottom>>8)
+	}
+}
+
+func TestPreprocessStripOnly(t *testing.T) {
+	orig := testJPEG(64, 64)
+	data := withEXIF(orig, 1)
+	out, _, err := (&PreprocessOptions{}).apply("detectface", DefaultImageLimits, data)
+	if err != nil {
+		t.Errorf("apply failed: %s\n", err)
+		return
+	}
+	//不需要改动像素时只去除元数据, 图像数据不变
+	if !bytes.Equal(out, orig) {
+		t.Errorf("stripped JPEG differs from original: %d bytes, want %d\n", len(out), len(orig))
+	}
+
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 50, 50)))
+	p := buf.Bytes()
+	iend := len(p) - 12
+	chunk := []byte{0, 0, 0, 3, 't', 'E', 'X', 't', 'G', 'P', 'S', 0, 0, 0, 0}
+	withText := append(append(append([]byte{}, p[:iend]...), chunk...), p[iend:]...)
+	if out := stripPNGMetadata(withText); !bytes.Equal(out, p) {
+		t.Errorf("stripPNGMetadata kept text chunk\n")
+	}
+}
+
+func TestPreprocessDownscale(t *testing.T) {
+	o := &PreprocessOptions{
+		MaxDimension:        map[string]int{"generalocr": 600},
+		DefaultMaxDimension: 300,
+	}
+	data := testJPEG(1200, 800)
+	for _, c := range []struct {
+		ifname string
+		w, h   int
+	}{
+		{"generalocr", 600, 400},
+		{"detectface", 300, 200},
+	} {
+		out, _, err := o.apply(c.ifname, DefaultImageLimits, data)
+		if err != nil {
+			t.Errorf("%s: apply failed: %s\n", c.ifname, err)
+			continue
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+		if err != nil || cfg.Width != c.w || cfg.Height != c.h {
+			t.Errorf("%s: size = %dx%d, %v, want %dx%d\n", c.ifname, cfg.Width, cfg.Height, err, c.w, c.h)
+		}
+	}
+}
+
+func TestPreprocessTargetBytes(t *testing.T) {
+	data := noiseJPEG(400, 400)
+	o := &PreprocessOptions{TargetBytes: 30000}
+	out, _, err := o.apply("imagetag", DefaultImageLimits, data)
+	if err != nil {
+		t.Errorf("apply failed: %s\n", err)
+		return
+	}
+	if len(out) > 30000 {
+		t.Errorf("preprocessed size = %d, want <= 30000\n", len(out))
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("Decode failed: %s\n", err)
+	}
+}
+
+func TestPreprocessImageField(t *testing.T) {
+	y := Init(AppSign{}, DefaultHost)
+	big := testJPEG(4200, 60)
+	if _, _, err := y.imageField("imagetag", ImageBytes(big)); err == nil {
+		t.Errorf("imageField accepted oversized image without preprocessing\n")
+	}
+	y.SetPreprocess(&PreprocessOptions{})
+	if _, _, err := y.imageField("imagetag", ImageBytes(big)); err != nil {
+		t.Errorf("imageField with preprocessing failed: %s\n", err)
+	}
+	y.SetPreprocess(nil)
+	if _, _, err := y.imageField("imagetag", ImageBytes(big)); err == nil {
+		t.Errorf("preprocessing still enabled after SetPreprocess(nil)\n")
+	}
+}
+
+//hugePNG 返回像素数据只有8x8、但IHDR声明为w*h的PNG
+func hugePNG(w, h uint32) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)))
+	data := buf.Bytes()
+	//IHDR数据位于偏移16, CRC覆盖类型和数据
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestDecodePixelLimit(t *testing.T) {
+	data := hugePNG(60000, 60000)
+	if _, _, err := (&PreprocessOptions{}).apply("detectface", DefaultImageLimits, data); !isValidationError(err) {
+		t.Errorf("apply with a huge image: err = %v\n", err)
+	}
+	y := Init(AppSign{}, "")
+	_, _, err := y.decodeImage("detectface", ImageBytes(data))
+	if ve, ok := err.(*ValidationError); !ok || ve.Endpoint != "detectface" {
+		t.Errorf("decodeImage with a huge image: err = %v\n", err)
+	}
+	if _, err := decodeAny(FormatPNG, data); !isValidationError(err) {
+		t.Errorf("decodeAny with a huge image: err = %v\n", err)
+	}
+	if _, err := decodeAny(FormatPNG, hugePNG(8, 8)); err != nil {
+		t.Errorf("decodeAny(8x8) failed: %s\n", err)
+	}
+}
+
+func isValidationError(err error) bool {
+	_, ok := err.(*ValidationError)
+	return ok
+}
@@ -0,0 +1,319 @@
+/*
+* File Name:	request.go
+* Description:  以结构体传递参数的请求, 新增参数不影响已有调用
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"fmt"
+)
+
+const (
+	//DefaultTopN MultiFaceIdentify每张人脸默认返回的候选人个数
+	DefaultTopN = 5
+	//DefaultMinFaceSize MultiFaceIdentify默认的最小人脸尺寸
+	DefaultMinFaceSize = 40
+)
+
+func requireImage(field string, img Image) error {
+	if img.empty() {
+		return invalid(field, "image is required")
+	}
+	return nil
+}
+
+//DetectFaceRequest 人脸检测参数
+type DetectFaceRequest struct {
+	Image   Image //待检测的图片
+	BigFace bool  //大脸模式, 只检测最大的人脸
+}
+
+//Validate 检查参数
+func (r DetectFaceRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//FaceShapeRequest 五官定位参数
+type FaceShapeRequest struct {
+	Image   Image //待检测的图片
+	BigFace bool  //大脸模式, 只检测最大的人脸
+}
+
+//Validate 检查参数
+func (r FaceShapeRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//FaceCompareRequest 人脸比对参数
+type FaceCompareRequest struct {
+	ImageA Image
+	ImageB Image
+}
+
+//Validate 检查参数
+func (r FaceCompareRequest) Validate() error {
+	if err := requireImage("ImageA", r.ImageA); err != nil {
+		return err
+	}
+	return requireImage("ImageB", r.ImageB)
+}
+
+//FaceVerifyRequest 人脸验证参数
+type FaceVerifyRequest struct {
+	PersonID string //待验证的Person
+	Image    Image
+}
+
+//Validate 检查参数
+func (r FaceVerifyRequest) Validate() error {
+	if err := requireID("PersonID", r.PersonID); err != nil {
+		return err
+	}
+	return requireImage("Image", r.Image)
+}
+
+//FaceIdentifyRequest 人脸识别参数
+type FaceIdentifyRequest struct {
+	GroupID string //候选人组id
+	Image   Image
+}
+
+//Validate 检查参数
+func (r FaceIdentifyRequest) Validate() error {
+	if err := requireID("GroupID", r.GroupID); err != nil {
+		return err
+	}
+	return requireImage("Image", r.Image)
+}
+
+//MultiFaceIdentifyRequest 多人脸检索参数, GroupID和GroupIDs必须且只能设置一个
+type MultiFaceIdentifyRequest struct {
+	GroupID  string   //候选人组id
+	GroupIDs []string //候选人组id列表
+	Image    Image
+	TopN     int //每张人脸返回的候选人个数, 0表示DefaultTopN
+	MinSize  int //最小人脸尺寸(像素), 0表示DefaultMinFaceSize
+}
+
+//Validate 检查参数
+func (r MultiFaceIdentifyRequest) Validate() error {
+	if r.GroupID == "" && len(r.GroupIDs) == 0 {
+		return invalid("GroupID", "one of GroupID and GroupIDs is required")
+	}
+	if r.GroupID != "" && len(r.GroupIDs) != 0 {
+		return invalid("GroupID", "GroupID and GroupIDs are mutually exclusive")
+	}
+	for i, g := range r.GroupIDs {
+		if err := requireID(fmt.Sprintf("GroupIDs[%d]", i), g); err != nil {
+			return err
+		}
+	}
+	if r.TopN < 0 {
+		return invalid("TopN", "must not be negative")
+	}
+	if r.MinSize < 0 {
+		return invalid("MinSize", "must not be negative")
+	}
+	return requireImage("Image", r.Image)
+}
+
+//NewPersonRequest 个体创建参数
+type NewPersonRequest struct {
+	PersonID   string
+	PersonName string   //名字, 可选
+	GroupIDs   []string //加入到组的列表
+	Image      Image
+	Tag        string //备注信息, 可选
+}
+
+//Validate 检查参数
+func (r NewPersonRequest) Validate() error {
+	if err := requireID("PersonID", r.PersonID); err != nil {
+		return err
+	}
+	if len(r.GroupIDs) == 0 {
+		return invalid("GroupIDs", "at least one group is required")
+	}
+	for i, g := range r.GroupIDs {
+		if err := requireID(fmt.Sprintf("GroupIDs[%d]", i), g); err != nil {
+			return err
+		}
+	}
+	return requireImage("Image", r.Image)
+}
+
+//AddFaceRequest 增加人脸参数
+type AddFaceRequest struct {
+	PersonID string
+	Images   []Image
+	Tag      string //备注信息, 可选
+}
+
+//Validate 检查参数
+func (r AddFaceRequest) Validate() error {
+	if err := requireID("PersonID", r.PersonID); err != nil {
+		return err
+	}
+	if len(r.Images) == 0 {
+		return invalid("Images", "at least one image is required")
+	}
+	for i, img := range r.Images {
+		if err := requireImage(fmt.Sprintf("Images[%d]", i), img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//IdcardOcrRequest 身份证OCR参数
+type IdcardOcrRequest struct {
+	Image    Image
+	CardType int32  //0代表正面, 1代表反面
+	Seq      string //序列号
+}
+
+//Validate 检查参数
+func (r IdcardOcrRequest) Validate() error {
+	if r.CardType != 0 && r.CardType != 1 {
+		return invalid("CardType", "must be 0 (front) or 1 (back)")
+	}
+	return requireImage("Image", r.Image)
+}
+
+//DriverLicenseOcrRequest 行驶证&驾驶证OCR参数
+type DriverLicenseOcrRequest struct {
+	Image Image
+	Type  int32  //0代表行驶证, 1代表驾驶证
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r DriverLicenseOcrRequest) Validate() error {
+	if r.Type != 0 && r.Type != 1 {
+		return invalid("Type", "must be 0 (vehicle license) or 1 (driver license)")
+	}
+	return requireImage("Image", r.Image)
+}
+
+//FuzzyDetectRequest 检测图片的模糊度参数
+type FuzzyDetectRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r FuzzyDetectRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//FoodDetectRequest 美食检测参数
+type FoodDetectRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r FoodDetectRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//ImageTagRequest 图片分类参数
+type ImageTagRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r ImageTagRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//ImagePornRequest 图片鉴黄参数
+type ImagePornRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r ImagePornRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//ImageTerrorismRequest 图片暴恐检测参数
+type ImageTerrorismRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r ImageTerrorismRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//CarClassifyRequest 车辆属性识别参数
+type CarClassifyRequest struct {
+	Image     Image
+	SessionID string //会话标识
+}
+
+//Validate 检查参数
+func (r CarClassifyRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//BCOcrRequest 名片OCR识别参数
+type BCOcrRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r BCOcrRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//GeneralOcrRequest 通用OCR识别参数
+type GeneralOcrRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r GeneralOcrRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//CreditCardOcrRequest 银行卡OCR识别参数
+type CreditCardOcrRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r CreditCardOcrRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//BizLicenseOcrRequest 营业执照OCR识别参数
+type BizLicenseOcrRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r BizLicenseOcrRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
+
+//PlateOcrRequest 车牌OCR识别参数
+type PlateOcrRequest struct {
+	Image Image
+	Seq   string //序列号
+}
+
+//Validate 检查参数
+func (r PlateOcrRequest) Validate() error {
+	return requireImage("Image", r.Image)
+}
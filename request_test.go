@@ -0,0 +1,87 @@
+/*
+* File Name:	request_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"testing"
+)
+
+func TestRequestValidate(t *testing.T) {
+	img := ImageBytes(testJPEG(64, 64))
+	for _, c := range []struct {
+		req   interface{ Validate() error }
+		field string //空表示合法
+	}{
+		{DetectFaceRequest{Image: img}, ""},
+		{DetectFaceRequest{}, "Image"},
+		{FaceCompareRequest{ImageA: img}, "ImageB"},
+		{FaceVerifyRequest{Image: img}, "PersonID"},
+		{FaceIdentifyRequest{GroupID: "g", Image: ImageURL("http://example.com/a.jpg")}, ""},
+		{MultiFaceIdentifyRequest{Image: img}, "GroupID"},
+		{MultiFaceIdentifyRequest{GroupID: "g", GroupIDs: []string{"h"}, Image: img}, "GroupID"},
+		{MultiFaceIdentifyRequest{GroupIDs: []string{"g", ""}, Image: img}, "GroupIDs[1]"},
+		{MultiFaceIdentifyRequest{GroupID: "g", TopN: -1, Image: img}, "TopN"},
+		{MultiFaceIdentifyRequest{GroupIDs: []string{"g"}, Image: img}, ""},
+		{NewPersonRequest{PersonID: "p", Image: img}, "GroupIDs"},
+		{NewPersonRequest{PersonID: "p", GroupIDs: []string{"g"}, Image: img}, ""},
+		{AddFaceRequest{PersonID: "p"}, "Images"},
+		{AddFaceRequest{PersonID: "p", Images: []Image{img, {}}}, "Images[1]"},
+		{AddFaceRequest{Images: []Image{img}}, "PersonID"},
+		{IdcardOcrRequest{Image: img, CardType: 2}, "CardType"},
+		{DriverLicenseOcrRequest{Image: img, Type: 1}, ""},
+		{GeneralOcrRequest{Seq: "s"}, "Image"},
+	} {
+		err := c.req.Validate()
+		if c.field == "" {
+			if err != nil {
+				t.Errorf("%#v.Validate() = %v, want nil\n", c.req, err)
+			}
+			continue
+		}
+		ve, ok := err.(*ValidationError)
+		if !ok || ve.Field != c.field {
+			t.Errorf("%#v.Validate() = %v, want ValidationError on %s\n", c.req, err, c.field)
+		}
+	}
+}
+
+func TestMultiFaceIdentifyRequest(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := newEchoServer(&bodies)
+	defer srv.Close()
+	as, _ := NewAppSign(1, "id", "key", "user")
+	y := Init(as, srv.URL)
+
+	img := ImageBytes(testJPEG(64, 64))
+	y.MultiFaceIdentifyRequest(MultiFaceIdentifyRequest{GroupID: "g", Image: img})
+	y.MultiFaceIdentifyRequest(MultiFaceIdentifyRequest{GroupID: "g", Image: img, TopN: 3, MinSize: 80})
+	y.MultiFaceIdentifyImage("g", nil, img, 0, 0)
+	if _, err := y.MultiFaceIdentifyRequest(MultiFaceIdentifyRequest{Image: img}); err == nil {
+		t.Errorf("invalid request did not fail\n")
+	}
+	if _, err := y.MultiFaceIdentifyImage("g", []string{"h"}, img, 0, 0); err == nil {
+		t.Errorf("MultiFaceIdentifyImage with both groupID and GroupIds did not fail\n")
+	}
+	//旧接口保持原来的行为, groupID优先
+	if _, err := y.MultiFaceIdentify("g", []string{"h"}, testJPEG(64, 64), 0, 0, 0); err != nil {
+		t.Errorf("legacy MultiFaceIdentify with both groupID and GroupIds failed: %s\n", err)
+	}
+	if len(bodies) != 4 {
+		t.Errorf("got %d requests, want 4 (invalid requests must not be sent)\n", len(bodies))
+		return
+	}
+	for i, want := range [][2]float64{{DefaultTopN, DefaultMinFaceSize}, {3, 80}, {DefaultTopN, DefaultMinFaceSize}} {
+		if bodies[i]["topn"] != want[0] || bodies[i]["min_size"] != want[1] {
+			t.Errorf("request %d topn/min_size = %v/%v, want %v\n", i, bodies[i]["topn"], bodies[i]["min_size"], want)
+		}
+	}
+	for _, i := range []int{2, 3} {
+		if bodies[i]["group_id"] != "g" || bodies[i]["group_ids"] != nil {
+			t.Errorf("request %d with groupID sent %v\n", i, bodies[i])
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+* File Name:	rotate.go
+* Description:  OCR结果为空或置信度低时, 把图片旋转90/180/270度重试, 采用得分最高的方向
+* Created:	2026-10-18
+ */
+
+package youtu
+
+//DefaultAutoRotateConfidence 不再旋转重试的默认平均置信度(0~1)
+const DefaultAutoRotateConfidence = 0.7
+
+//rotateOrientations 顺时针旋转角度对应的EXIF方向值
+var rotateOrientations = map[int]int{90: 6, 180: 3, 270: 8}
+
+//AutoRotateOptions 自动旋转选项
+type AutoRotateOptions struct {
+	MinConfidence float64 //平均置信度(0~1)达到该值时不再旋转, 为0时使用DefaultAutoRotateConfidence
+	Angles        []int   //依次尝试的顺时针旋转角度, 为空时为90, 180, 270
+}
+
+//OcrRotation 自动旋转的结果
+type OcrRotation struct {
+	Angle  int             //采用的结果对应的顺时针旋转角度, 0表示未旋转
+	Score  float64         //采用的结果的平均置信度(0~1), 结果为空或出错时为0
+	Scores map[int]float64 //识别成功的各角度的得分
+}
+
+//autoRotate 先识别原图, 得分低于阈值时依次识别旋转后的图片, 达到阈值即停止, 返回得分最高的一次.
+//recognize识别图片并返回结果的得分. 旋转后的图片在本地解码摆正后生成, 记录原图到旋转后图片的变换.
+//只有原图识别失败时返回错误; 无法在本地解码(如未SetURLFetch的url图片)时不再旋转, 旋转后识别失败的角度被跳过
+func (y *Youtu) autoRotate(ifname string, img Image, o AutoRotateOptions, recognize func(Image) (float64, error)) (best int, rot OcrRotation, err error) {
+	min := o.MinConfidence
+	if min <= 0 {
+		min = DefaultAutoRotateConfidence
+	}
+	angles := o.Angles
+	if len(angles) == 0 {
+		angles = []int{90, 180, 270}
+	}
+	for _, a := range angles {
+		if _, ok := rotateOrientations[a]; !ok {
+			return 0, rot, invalid("Angles", "rotation must be 90, 180 or 270")
+		}
+	}
+	rot.Scores = make(map[int]float64)
+	if rot.Score, err = recognize(img); err != nil {
+		return
+	}
+	rot.Scores[0] = rot.Score
+	best = 0
+	if rot.Score >= min {
+		return
+	}
+	m, t, derr := y.decodeImage(ifname, img)
+	if derr != nil {
+		return
+	}
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	for i, a := range angles {
+		orientation := rotateOrientations[a]
+		rotated := ImageJPEG(orient(m, orientation), DefaultNormalizeQuality).
+			WithTransform(t.Then(OrientationTransform(orientation, w, h)))
+		score, rerr := recognize(rotated)
+		if rerr != nil {
+			continue
+		}
+		rot.Scores[a] = score
+		if score > rot.Score {
+			best, rot.Angle, rot.Score = i+1, a, score
+		}
+		if score >= min {
+			return
+		}
+	}
+	return
+}
+
+//generalOcrScore 通用OCR结果的平均置信度, 有逐字置信度时按字计算
+func generalOcrScore(rsp GeneralOcrRsp) float64 {
+	if rsp.ErrorCode != 0 || len(rsp.Items) == 0 {
+		return 0
+	}
+	var sum float64
+	n := 0
+	for _, it := range rsp.Items {
+		if len(it.Words) == 0 {
+			sum += float64(it.Itemconf)
+			n++
+			continue
+		}
+		for _, w := range it.Words {
+			sum += float64(w.Confidence)
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+//idcardOcrScore 身份证OCR各文字字段置信度(0~100)的平均值, 换算到0~1
+func idcardOcrScore(rsp IdcardOcrRsp) float64 {
+	if rsp.ErrorCode != 0 {
+		return 0
+	}
+	var sum float64
+	n := 0
+	for _, c := range [][]int32{
+		rsp.NameConfidenceAll, rsp.SexConfidenceAll, rsp.NationConfidenceAll, rsp.BirthConfidenceAll,
+		rsp.AddressConfidenceAll, rsp.IdConfidenceAll, rsp.ValidDateConfidenceAll, rsp.AuthorityConfidenceAll,
+	} {
+		for _, v := range c {
+			sum += float64(v)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n) / 100
+}
+
+//GeneralOcrAutoRotate 同GeneralOcrImage, 结果为空或平均置信度低于阈值时旋转图片重试.
+//旋转后的结果坐标相对于旋转后的图片, Transform记录原图到旋转后图片的变换, 可用Original映射回原图
+func (y *Youtu) GeneralOcrAutoRotate(img Image, seq string, o AutoRotateOptions) (rsp GeneralOcrRsp, rot OcrRotation, err error) {
+	var results []GeneralOcrRsp
+	best, rot, err := y.autoRotate("generalocr", img, o, func(img Image) (float64, error) {
+		r, err := y.GeneralOcrRequest(GeneralOcrRequest{Image: img, Seq: seq})
+		results = append(results, r)
+		return generalOcrScore(r), err
+	})
+	if err == nil {
+		rsp = results[best]
+	}
+	return
+}
+
+//IdcardOcrAutoRotate 同IdcardOcrImage, 出错或字段平均置信度低于阈值时旋转图片重试
+func (y *Youtu) IdcardOcrAutoRotate(img Image, cardType int32, seq string, o AutoRotateOptions) (rsp IdcardOcrRsp, rot OcrRotation, err error) {
+	var results []IdcardOcrRsp
+	best, rot, err := y.autoRotate("idcardocr", img, o, func(img Image) (float64, error) {
+		r, err := y.IdcardOcrRequest(IdcardOcrRequest{Image: img, CardType: cardType, Seq: seq})
+		results = append(results, r)
+		return idcardOcrScore(r), err
+	})
+	if err == nil {
+		rsp = results[best]
+	}
+	return
+}
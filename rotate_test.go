@@ -0,0 +1,120 @@
+/*
+* File Name:	rotate_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//markedImage 返回w*h的白色图片, 左上角有黑色标记
+func markedImage(w, h int) *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(m, m.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(m, image.Rect(0, 0, w/4, h/4), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	return m
+}
+
+//markCorner 返回图片中黑色标记所在的角: 0左上, 1右上, 2右下, 3左下
+func markCorner(m image.Image) int {
+	b := m.Bounds()
+	for i, p := range []image.Point{{2, 2}, {b.Dx() - 3, 2}, {b.Dx() - 3, b.Dy() - 3}, {2, b.Dy() - 3}} {
+		if c, _, _, _ := m.At(p.X, p.Y).RGBA(); c < 0x8000 {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestOcrAutoRotate(t *testing.T) {
+	//标记在右上角时(原图顺时针旋转90度)识别正确
+	var corners []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GeneralOcrReq
+		json.NewDecoder(r.Body).Decode(&req)
+		data, _ := base64.StdEncoding.DecodeString(req.Image)
+		m, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			w.Write([]byte(`{"errorcode":-1}`))
+			return
+		}
+		corner := markCorner(m)
+		corners = append(corners, corner)
+		if corner == 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path == "/youtu/ocrapi/idcardocr" {
+			if corner != 2 {
+				w.Write([]byte(`{"errorcode":-5208,"errormsg":"no card"}`))
+				return
+			}
+			w.Write([]byte(`{"errorcode":0,"name":"张三","name_confidence_all":[90,94],"id_confidence_all":[98]}`))
+			return
+		}
+		rsp := GeneralOcrRsp{Items: []ItemContent{{Itemstring: "abc", Itemconf: 0.2, Itemcoord: Coordinate{0, 0, 60, 10}}}}
+		if corner == 1 {
+			rsp.Items[0].Itemconf = 0.95
+			rsp.Items[0].Words = []Word{{Character: "a", Confidence: 0.9}, {Character: "b", Confidence: 1}}
+		}
+		json.NewEncoder(w).Encode(rsp)
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+	img := ImagePNG(markedImage(100, 60))
+
+	rsp, rot, err := y.GeneralOcrAutoRotate(img, "", AutoRotateOptions{})
+	if err != nil {
+		t.Errorf("GeneralOcrAutoRotate failed: %s\n", err)
+		return
+	}
+	if rot.Angle != 90 || math.Abs(rot.Score-0.95) > 1e-6 || len(rot.Scores) != 2 || len(corners) != 2 || corners[1] != 1 {
+		t.Errorf("GeneralOcrAutoRotate rotation = %+v, corners %v\n", rot, corners)
+	}
+	//旋转后图片顶部的一条对应原图左侧的一列
+	if got := rsp.Original().Items[0].Itemcoord; got != (Coordinate{0, 0, 10, 60}) {
+		t.Errorf("GeneralOcrAutoRotate Original itemcoord = %+v\n", got)
+	}
+
+	//都低于阈值时返回得分最高的一次
+	corners = nil
+	_, rot, err = y.GeneralOcrAutoRotate(img, "", AutoRotateOptions{MinConfidence: 0.99, Angles: []int{180, 90, 270}})
+	if err != nil || rot.Angle != 90 || len(corners) != 4 {
+		t.Errorf("GeneralOcrAutoRotate below threshold = %+v, %v, %d requests\n", rot, err, len(corners))
+	}
+
+	//旋转后识别失败的角度被跳过
+	corners = nil
+	_, rot, err = y.GeneralOcrAutoRotate(img, "", AutoRotateOptions{Angles: []int{270, 90}})
+	if _, tried := rot.Scores[270]; err != nil || rot.Angle != 90 || tried {
+		t.Errorf("GeneralOcrAutoRotate with a failed rotation = %+v, %v\n", rot, err)
+	}
+
+	//url图片无法在本地解码时返回原图的结果
+	rsp, rot, err = y.GeneralOcrAutoRotate(ImageURL("http://example.com/a.png"), "", AutoRotateOptions{})
+	if err != nil || rot.Angle != 0 || len(rot.Scores) != 1 || rsp.ErrorCode != -1 {
+		t.Errorf("GeneralOcrAutoRotate with an url = %+v, %+v, %v\n", rsp, rot, err)
+	}
+
+	corners = nil
+	id, rot, err := y.IdcardOcrAutoRotate(img, 0, "", AutoRotateOptions{})
+	if err != nil || id.Name != "张三" || rot.Angle != 180 || rot.Score < 0.93 || rot.Score > 0.95 {
+		t.Errorf("IdcardOcrAutoRotate = %q, %+v, %v\n", id.Name, rot, err)
+	}
+
+	if _, _, err := y.GeneralOcrAutoRotate(img, "", AutoRotateOptions{Angles: []int{45}}); err == nil {
+		t.Errorf("GeneralOcrAutoRotate accepted a 45 degree rotation\n")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+* File Name:	annotate.go
+* Description:  调用接口并把结果画到图片上
+* Created:	2026-10-18
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ochapman/youtu"
+)
+
+const usage = `usage:
+  annotate -cred FILE [-api detectface|faceshape|multifaceidentify|generalocr|carclassify] [-group ID] [-o OUT] IMAGE
+
+OUT defaults to IMAGE with an .annotated.png suffix; a .jpg/.jpeg OUT is written as JPEG.
+The passphrase of the credentials file is read from $YOUTU_PASSPHRASE.
+`
+
+func main() {
+	credFile := flag.String("cred", "", "encrypted credentials file created by youtucred")
+	api := flag.String("api", "detectface", "interface whose result is drawn")
+	group := flag.String("group", "", "group id for multifaceidentify")
+	out := flag.String("o", "", "output image")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if *credFile == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = flag.Arg(0) + ".annotated.png"
+	}
+
+	as, err := youtu.LoadCredentials(*credFile, []byte(os.Getenv("YOUTU_PASSPHRASE")))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "LoadCredentials failed: %s\n", err)
+		os.Exit(1)
+	}
+	yt := youtu.Init(as, youtu.DefaultHost)
+	img := youtu.ImageFile(flag.Arg(0))
+	canvas, err := yt.NewCanvas(img)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "NewCanvas failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	var errorCode int
+	switch *api {
+	case "detectface":
+		rsp, e := yt.DetectFaceImage(img, false)
+		canvas.DetectFace(rsp)
+		err, errorCode = e, rsp.ErrorCode
+	case "faceshape":
+		rsp, e := yt.FaceShapeImage(img, false)
+		canvas.FaceShape(rsp)
+		err, errorCode = e, rsp.ErrorCode
+	case "multifaceidentify":
+		rsp, e := yt.MultiFaceIdentifyRequest(youtu.MultiFaceIdentifyRequest{GroupID: *group, Image: img})
+		canvas.MultiFaceIdentify(rsp)
+		err, errorCode = e, rsp.ErrorCode
+	case "generalocr":
+		rsp, e := yt.GeneralOcrImage(img, "")
+		canvas.GeneralOcr(rsp)
+		err, errorCode = e, int(rsp.ErrorCode)
+	case "carclassify":
+		rsp, e := yt.CarClassifyImage(img, "")
+		canvas.CarClassify(rsp)
+		err, errorCode = e, int(rsp.ErrorCode)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown api %q\n", *api)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %s\n", *api, err)
+		os.Exit(1)
+	}
+	if errorCode != 0 {
+		fmt.Fprintf(os.Stderr, "%s returned errorcode %d\n", *api, errorCode)
+		os.Exit(1)
+	}
+	if err = canvas.Save(*out); err != nil {
+		fmt.Fprintf(os.Stderr, "Save failed: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(*out)
+}
@@ -7,34 +7,38 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 
 	"github.com/ochapman/youtu"
 )
 
 func main() {
+	credFile := flag.String("cred", "", "encrypted credentials file created by youtucred, passphrase is read from $YOUTU_PASSPHRASE")
+	flag.Parse()
+
 	//Register your app on http://open.youtu.qq.com
 	//Get the following details
-	appID := uint32()
+	appID := uint32(0)
 	secretID := ""
 	secretKey := ""
 	userID := ""
 
-	as, err := youtu.NewAppSign(appID, secretID, secretKey, userID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "NewAppSign() failed: %s\n", err)
-		return
+	var as youtu.AppSign
+	var err error
+	if *credFile != "" {
+		as, err = youtu.LoadCredentials(*credFile, []byte(os.Getenv("YOUTU_PASSPHRASE")))
+	} else {
+		as, err = youtu.NewAppSign(appID, secretID, secretKey, userID)
 	}
-	imgData, err := ioutil.ReadFile("../../testdata/imageA.jpg")
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "ReadFile() failed: %s\n", err)
+		fmt.Fprintf(os.Stderr, "AppSign failed: %s\n", err)
 		return
 	}
-    //yt := youtu.Init(as, youtu.TencentYunHost)
+	//yt := youtu.Init(as, youtu.TencentYunHost)
 	yt := youtu.Init(as, youtu.DefaultHost)
-	df, err := yt.DetectFace(imgData, false, 0)
+	df, err := yt.DetectFaceImage(youtu.ImageFile("../../testdata/imageA.jpg"), false)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "DetectFace() failed: %s", err)
 		return
@@ -0,0 +1,73 @@
+/*
+* File Name:	youtucred.go
+* Description:  创建和更换加密凭据文件
+* Created:	2026-10-18
+ */
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ochapman/youtu"
+)
+
+const usage = `usage:
+  youtucred create -app-id ID -secret-id SID -user-id UID FILE
+  youtucred rotate FILE
+
+The secret key and passphrases are read from stdin, one per line,
+so they do not end up in shell history or the process list.
+`
+
+func readLine(in *bufio.Reader, prompt string) []byte {
+	fmt.Fprint(os.Stderr, prompt)
+	line, _ := in.ReadString('\n')
+	return []byte(strings.TrimRight(line, "\r\n"))
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	in := bufio.NewReader(os.Stdin)
+
+	var err error
+	switch os.Args[1] {
+	case "create":
+		fs := flag.NewFlagSet("create", flag.ExitOnError)
+		appID := fs.Uint("app-id", 0, "AppID")
+		secretID := fs.String("secret-id", "", "SecretID")
+		userID := fs.String("user-id", "", "UserID")
+		fs.Parse(os.Args[2:])
+		if fs.NArg() != 1 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		secretKey := readLine(in, "SecretKey: ")
+		var as youtu.AppSign
+		as, err = youtu.NewAppSign(uint32(*appID), *secretID, string(secretKey), *userID)
+		if err == nil {
+			err = youtu.SaveCredentials(fs.Arg(0), as, readLine(in, "Passphrase: "))
+		}
+	case "rotate":
+		if len(os.Args) != 3 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		oldPass := readLine(in, "Old passphrase: ")
+		newPass := readLine(in, "New passphrase: ")
+		err = youtu.RotateCredentials(os.Args[2], oldPass, newPass)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "youtucred %s failed: %s\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+* File Name:	secret.go
+* Description:  格式化输出、日志和panic中隐藏密钥
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+const redacted = "[REDACTED]"
+
+//maskSecretID 只保留SecretID的前4个字符
+func maskSecretID(id string) string {
+	if len(id) < 8 {
+		return redacted
+	}
+	return id[:4] + "****"
+}
+
+//String 返回不含密钥的描述, panic(as)时也使用它
+func (as AppSign) String() string {
+	return fmt.Sprintf("AppSign{appID: %d, secretID: %q, secretKey: %s, userID: %q}",
+		as.appID, maskSecretID(as.secretID), redacted, as.userID)
+}
+
+//GoString 实现fmt.GoStringer, %#v不打印密钥
+func (as AppSign) GoString() string {
+	return "youtu." + as.String()
+}
+
+//Format 实现fmt.Formatter, 任何动词都只输出隐藏密钥后的描述
+func (as AppSign) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, as.GoString())
+		return
+	}
+	fmt.Fprint(f, as.String())
+}
+
+//LogValue 实现slog.LogValuer
+func (as AppSign) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("app_id", uint64(as.appID)),
+		slog.String("secret_id", maskSecretID(as.secretID)),
+		slog.String("secret_key", redacted),
+		slog.String("user_id", as.userID),
+	)
+}
+
+//String 返回不含密钥的描述
+func (y Youtu) String() string {
+	return fmt.Sprintf("Youtu{host: %q, tenant: %q, appSign: %s}", y.host, y.tenant, y.appSign)
+}
+
+//GoString 实现fmt.GoStringer
+func (y Youtu) GoString() string {
+	return "youtu." + y.String()
+}
+
+//Format 实现fmt.Formatter, 值和指针都不会打印密钥和租户的AppSign
+func (y Youtu) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, y.GoString())
+		return
+	}
+	fmt.Fprint(f, y.String())
+}
+
+//LogValue 实现slog.LogValuer
+func (y Youtu) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("host", y.host),
+		slog.String("tenant", y.tenant),
+		slog.Any("app_sign", y.appSign),
+	)
+}
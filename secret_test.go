@@ -0,0 +1,99 @@
+/*
+* File Name:	secret_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testSecretKey = "s3cr3t-k3y-0123456789"
+
+func assertNoSecret(t *testing.T, what, out string) {
+	if strings.Contains(out, testSecretKey) {
+		t.Errorf("%s leaks secret key: %s\n", what, out)
+	}
+	if strings.Contains(out, fmt.Sprintf("%x", testSecretKey)) {
+		t.Errorf("%s leaks hex encoded secret key: %s\n", what, out)
+	}
+}
+
+func TestFormatRedactsSecret(t *testing.T) {
+	as, _ := NewAppSign(1000061, "AKIDsecretid", testSecretKey, "10000")
+	y := Init(as, DefaultHost)
+	y.AddTenant("bu", as)
+	tenant, _ := y.Tenant("bu")
+	p, _ := NewPool(y, PoolMember{Tenant: "bu"})
+
+	values := []interface{}{as, &as, y, *y, tenant, p, []AppSign{as}, map[string]*Youtu{"y": y},
+		struct{ A AppSign }{as}}
+	verbs := []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%d"}
+	for _, v := range values {
+		for _, verb := range verbs {
+			assertNoSecret(t, fmt.Sprintf("Sprintf(%q, %T)", verb, v), fmt.Sprintf(verb, v))
+		}
+	}
+	if s := fmt.Sprint(as); !strings.Contains(s, "1000061") || strings.Contains(s, "AKIDsecretid") {
+		t.Errorf("AppSign String = %s\n", s)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("init", "app", as, "youtu", y)
+	assertNoSecret(t, "slog", buf.String())
+
+	func() {
+		defer func() {
+			assertNoSecret(t, "panic value", fmt.Sprint(recover()))
+		}()
+		panic(as)
+	}()
+}
+
+func TestDebugOutputRedactsSecret(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(GetGroupIDsRsp{})
+	}))
+	defer srv.Close()
+
+	as, _ := NewAppSign(1, "AKIDsecretid", testSecretKey, "user")
+	y := Init(as, srv.URL)
+	y.SetDebug(true)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Errorf("Pipe failed: %s\n", err)
+		return
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	_, err = y.GetGroupIDs()
+	os.Stdout, os.Stderr = stdout, stderr
+	w.Close()
+	out, _ := ioutil.ReadAll(r)
+	if err != nil {
+		t.Errorf("GetGroupIDs failed: %s\n", err)
+		return
+	}
+	assertNoSecret(t, "debug output", string(out))
+	if auth == "" || strings.Contains(string(out), auth) {
+		t.Errorf("debug output leaks Authorization header: %s\n", out)
+	}
+	if strings.Contains(string(out), "AKIDsecretid") {
+		t.Errorf("debug output leaks secret id: %s\n", out)
+	}
+}
@@ -12,12 +12,11 @@ import (
 	"encoding/base64"
 	"fmt"
 	"math/rand"
-	"time"
 )
 
 func (y *Youtu) orignalSign() string {
 	as := y.appSign
-	now := time.Now().Unix()
+	now := y.now().Unix()
 	rand.Seed(int64(now))
 	rnd := rand.Int31()
 	sign := fmt.Sprintf("a=%d&k=%s&e=%d&t=%d&r=%d&u=%s&f=",
@@ -29,14 +28,15 @@ func (y *Youtu) orignalSign() string {
 		as.userID)
 
 	if y.debug {
-		fmt.Printf("orignal sign: %s\n", sign)
+		fmt.Printf("orignal sign: a=%d&k=%s&e=%d&t=%d&r=%d&u=%s&f=\n",
+			as.appID, maskSecretID(as.secretID), now+expiredInterval, now, rnd, as.userID)
 	}
 	return sign
 }
 
 func (y *Youtu) sign() string {
 	origSign := y.orignalSign()
-	h := hmac.New(sha1.New, []byte(y.appSign.secretKey))
+	h := hmac.New(sha1.New, y.appSign.secretKey)
 	h.Write([]byte(origSign))
 	hm := h.Sum(nil)
 	//attach orig_sign to hm
@@ -0,0 +1,144 @@
+/*
+* File Name:	tenant.go
+* Description:  多租户: 一个客户端注册多个AppSign, 按调用选择
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	//ErrTenantNotFound 租户未注册
+	ErrTenantNotFound = errors.New("tenant not found")
+)
+
+//TenantStats 租户的请求统计
+type TenantStats struct {
+	Requests uint64 //请求次数
+	Failures uint64 //网络或HTTP错误次数
+}
+
+//shared 同一客户端下所有租户共享的状态
+type shared struct {
+	skew   int64 //时钟偏差(纳秒), 原子访问, 放在首位以保证64位对齐
+	client *http.Client
+
+	mu      sync.RWMutex
+	tenants map[string]AppSign
+	stats   map[string]*TenantStats
+
+	signExpiredCodes []int
+	limits           map[string]ImageLimits
+	preprocess       *PreprocessOptions
+	fetcher          *fetcher
+	normalize        *NormalizeOptions
+	blurGate         *BlurGateOptions
+}
+
+func newShared() *shared {
+	return &shared{
+		client: &http.Client{
+			Timeout: time.Duration(5 * time.Second),
+		},
+		tenants: make(map[string]AppSign),
+		stats:   make(map[string]*TenantStats),
+
+		signExpiredCodes: append([]int(nil), DefaultSignExpiredCodes...),
+	}
+}
+
+func (s *shared) record(tenant string, err error) {
+	s.mu.Lock()
+	st, ok := s.stats[tenant]
+	if !ok {
+		st = new(TenantStats)
+		s.stats[tenant] = st
+	}
+	st.Requests++
+	if err != nil {
+		st.Failures++
+	}
+	s.mu.Unlock()
+}
+
+//AddTenant 注册租户, 同名租户的AppSign会被替换
+func (y *Youtu) AddTenant(name string, as AppSign) {
+	y.shared.mu.Lock()
+	y.shared.tenants[name] = as
+	y.shared.mu.Unlock()
+}
+
+//RemoveTenant 删除租户
+func (y *Youtu) RemoveTenant(name string) {
+	y.shared.mu.Lock()
+	delete(y.shared.tenants, name)
+	y.shared.mu.Unlock()
+}
+
+//Tenants 返回已注册的租户名, 按名字排序
+func (y *Youtu) Tenants() []string {
+	y.shared.mu.RLock()
+	names := make([]string, 0, len(y.shared.tenants))
+	for name := range y.shared.tenants {
+		names = append(names, name)
+	}
+	y.shared.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+//Tenant 返回以租户name的AppSign发起请求的客户端,
+//它与y共享连接、租户表和统计, 可以并发使用
+func (y *Youtu) Tenant(name string) (*Youtu, error) {
+	y.shared.mu.RLock()
+	as, ok := y.shared.tenants[name]
+	y.shared.mu.RUnlock()
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+	t := *y
+	t.appSign = as
+	t.tenant = name
+	return &t, nil
+}
+
+//Stats 返回各租户的请求统计, 默认租户的名字为空
+func (y *Youtu) Stats() map[string]TenantStats {
+	y.shared.mu.RLock()
+	defer y.shared.mu.RUnlock()
+	stats := make(map[string]TenantStats, len(y.shared.stats))
+	for name, st := range y.shared.stats {
+		stats[name] = *st
+	}
+	return stats
+}
+
+type tenantKey struct{}
+
+//WithTenant 返回携带租户名的context, 配合FromContext使用
+func WithTenant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, name)
+}
+
+//TenantFromContext 返回ctx中的租户名
+func TenantFromContext(ctx context.Context) (name string, ok bool) {
+	name, ok = ctx.Value(tenantKey{}).(string)
+	return
+}
+
+//FromContext 按ctx中的租户名选择客户端, ctx中没有租户时返回y本身
+func (y *Youtu) FromContext(ctx context.Context) (*Youtu, error) {
+	name, ok := TenantFromContext(ctx)
+	if !ok {
+		return y, nil
+	}
+	return y.Tenant(name)
+}
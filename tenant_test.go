@@ -0,0 +1,78 @@
+/*
+* File Name:	tenant_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//newAppIDServer 返回一个把请求中的app_id作为group_ids返回的测试服务器
+func newAppIDServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AppID string `json:"app_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(GetGroupIDsRsp{GroupIDs: []string{req.AppID}})
+	}))
+}
+
+func TestTenant(t *testing.T) {
+	srv := newAppIDServer()
+	defer srv.Close()
+
+	def, _ := NewAppSign(1, "id1", "key1", "user1")
+	bu, _ := NewAppSign(2, "id2", "key2", "user2")
+	y := Init(def, srv.URL)
+	y.AddTenant("bu", bu)
+
+	if _, err := y.Tenant("missing"); err != ErrTenantNotFound {
+		t.Errorf("Tenant(missing): err = %v, want %v\n", err, ErrTenantNotFound)
+	}
+	tenant, err := y.Tenant("bu")
+	if err != nil {
+		t.Errorf("Tenant failed: %s\n", err)
+		return
+	}
+	if tenant.shared != y.shared {
+		t.Errorf("tenant client does not share state with its parent\n")
+	}
+
+	for _, c := range []struct {
+		yt   *Youtu
+		want string
+	}{{y, "1"}, {tenant, "2"}} {
+		rsp, err := c.yt.GetGroupIDs()
+		if err != nil {
+			t.Errorf("GetGroupIDs failed: %s\n", err)
+			return
+		}
+		if len(rsp.GroupIDs) != 1 || rsp.GroupIDs[0] != c.want {
+			t.Errorf("request app_id = %v, want %s\n", rsp.GroupIDs, c.want)
+		}
+	}
+
+	ctxYt, err := y.FromContext(WithTenant(context.Background(), "bu"))
+	if err != nil || ctxYt.appID() != "2" {
+		t.Errorf("FromContext = %v, %v\n", ctxYt, err)
+	}
+	if ctxYt, _ := y.FromContext(context.Background()); ctxYt != y {
+		t.Errorf("FromContext without tenant should return the default client\n")
+	}
+
+	stats := y.Stats()
+	if stats[""].Requests != 1 || stats["bu"].Requests != 1 {
+		t.Errorf("Stats = %v\n", stats)
+	}
+	if names := y.Tenants(); len(names) != 1 || names[0] != "bu" {
+		t.Errorf("Tenants = %v\n", names)
+	}
+}
@@ -0,0 +1,477 @@
+/*
+* File Name:	tiff.go
+* Description:  基本TIFF解码: 条带存储, 无压缩、PackBits、LZW和Deflate, 支持多页
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/ioutil"
+)
+
+var errTIFFFormat = errors.New("tiff: invalid format")
+
+//TIFF标签
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffPlanarConfig    = 284
+	tiffPredictor       = 317
+	tiffColorMap        = 320
+	tiffTileWidth       = 322
+	tiffExtraSamples    = 338
+)
+
+const (
+	//tiffMaxPages 最多解码的页数
+	tiffMaxPages = 1000
+	//tiffMaxPixels 每页最多的像素数, 防止很小的文件声明巨大的尺寸耗尽内存
+	tiffMaxPixels = 1 << 26
+)
+
+//tiffTags 解码用到的标签, parseTIFF只读取这些标签的值
+var tiffTags = map[uint16]bool{
+	tiffImageWidth: true, tiffImageLength: true, tiffBitsPerSample: true, tiffCompression: true,
+	tiffPhotometric: true, tiffStripOffsets: true, tiffSamplesPerPixel: true, tiffRowsPerStrip: true,
+	tiffStripByteCounts: true, tiffPlanarConfig: true, tiffPredictor: true, tiffColorMap: true,
+	tiffTileWidth: true, tiffExtraSamples: true,
+}
+
+//isTIFF 是否为TIFF文件头
+func isTIFF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*"))
+}
+
+type tiffIFD struct {
+	bo      binary.ByteOrder
+	data    []byte
+	entries map[uint16][]uint32
+}
+
+func (d tiffIFD) first(tag uint16, def uint32) uint32 {
+	if v := d.entries[tag]; len(v) > 0 {
+		return v[0]
+	}
+	return def
+}
+
+//parseTIFF 返回所有页的IFD
+func parseTIFF(data []byte) ([]tiffIFD, error) {
+	if !isTIFF(data) || len(data) < 8 {
+		return nil, errTIFFFormat
+	}
+	var bo binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		bo = binary.BigEndian
+	}
+	var ifds []tiffIFD
+	seen := make(map[uint32]bool)
+	//所有页共用的值数上限, 防止多个标签或多页指向同一段数据时反复分配
+	budget := len(data)
+	for off := bo.Uint32(data[4:]); off != 0; {
+		if seen[off] || len(ifds) >= tiffMaxPages || int(off)+2 > len(data) {
+			break
+		}
+		seen[off] = true
+		n := int(bo.Uint16(data[off:]))
+		end := int(off) + 2 + n*12
+		if end+4 > len(data) {
+			return nil, errTIFFFormat
+		}
+		d := tiffIFD{bo: bo, data: data, entries: make(map[uint16][]uint32)}
+		for i := 0; i < n; i++ {
+			e := data[int(off)+2+i*12:]
+			tag, typ, cnt := bo.Uint16(e), bo.Uint16(e[2:]), int(bo.Uint32(e[4:]))
+			if !tiffTags[tag] {
+				continue
+			}
+			var size int
+			switch typ {
+			case 1, 2, 6, 7: //BYTE, ASCII, SBYTE, UNDEFINED
+				size = 1
+			case 3, 8: //SHORT, SSHORT
+				size = 2
+			case 4, 9: //LONG, SLONG
+				size = 4
+			default:
+				continue
+			}
+			if cnt < 0 || cnt > budget/size {
+				return nil, errTIFFFormat
+			}
+			budget -= cnt * size
+			val := e[8:12]
+			if size*cnt > 4 {
+				p := int(bo.Uint32(e[8:]))
+				if p < 0 || p+size*cnt > len(data) {
+					return nil, errTIFFFormat
+				}
+				val = data[p:]
+			}
+			vs := make([]uint32, cnt)
+			for k := range vs {
+				switch size {
+				case 1:
+					vs[k] = uint32(val[k])
+				case 2:
+					vs[k] = uint32(bo.Uint16(val[k*2:]))
+				case 4:
+					vs[k] = bo.Uint32(val[k*4:])
+				}
+			}
+			d.entries[tag] = vs
+		}
+		ifds = append(ifds, d)
+		off = bo.Uint32(data[end:])
+	}
+	if len(ifds) == 0 {
+		return nil, errTIFFFormat
+	}
+	return ifds, nil
+}
+
+//decodeTIFF 解码第一页
+func decodeTIFF(data []byte) (image.Image, error) {
+	ifds, err := parseTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+	return ifds[0].decode()
+}
+
+//decodeTIFFPages 解码所有页
+func decodeTIFFPages(data []byte) ([]image.Image, error) {
+	ifds, err := parseTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]image.Image, len(ifds))
+	for i, d := range ifds {
+		if pages[i], err = d.decode(); err != nil {
+			return nil, fmt.Errorf("tiff page %d: %w", i+1, err)
+		}
+	}
+	return pages, nil
+}
+
+//tiffPageCount 页数, 不是TIFF时返回0
+func tiffPageCount(data []byte) int {
+	ifds, _ := parseTIFF(data)
+	return len(ifds)
+}
+
+func (d tiffIFD) decode() (image.Image, error) {
+	w := int(d.first(tiffImageWidth, 0))
+	h := int(d.first(tiffImageLength, 0))
+	if w <= 0 || h <= 0 || w > 1<<15 || h > 1<<15 {
+		return nil, errTIFFFormat
+	}
+	if _, tiled := d.entries[tiffTileWidth]; tiled {
+		return nil, errors.New("tiff: tiled images are not supported")
+	}
+	if d.first(tiffPlanarConfig, 1) != 1 {
+		return nil, errors.New("tiff: planar configuration 2 is not supported")
+	}
+	spp := int(d.first(tiffSamplesPerPixel, 1))
+	bps := int(d.first(tiffBitsPerSample, 1))
+	for _, b := range d.entries[tiffBitsPerSample] {
+		if int(b) != bps {
+			return nil, errors.New("tiff: mixed bits per sample")
+		}
+	}
+	photometric := d.first(tiffPhotometric, 1)
+	switch bps {
+	case 1, 2, 4, 8, 16:
+	default:
+		return nil, errTIFFFormat
+	}
+	if spp < 1 || spp > 4 || w*h > tiffMaxPixels {
+		return nil, errTIFFFormat
+	}
+	rowBytes := (w*spp*bps + 7) / 8
+	size := rowBytes * h
+
+	//解压所有条带, 每个条带最多解压出所需的字节数, 数据足够时才分配像素
+	offsets, counts := d.entries[tiffStripOffsets], d.entries[tiffStripByteCounts]
+	if len(offsets) == 0 || len(offsets) != len(counts) {
+		return nil, errTIFFFormat
+	}
+	compression := d.first(tiffCompression, 1)
+	if compression == 1 {
+		total := 0
+		for _, c := range counts {
+			total += int(c)
+		}
+		if total < size {
+			return nil, errTIFFFormat
+		}
+	}
+	rps := int(d.first(tiffRowsPerStrip, uint32(h)))
+	if rps <= 0 || rps > h {
+		rps = h
+	}
+	var strips [][]byte
+	n := 0
+	for i := range offsets {
+		rows := minInt(rps, h-i*rps)
+		if rows <= 0 {
+			break
+		}
+		o, c := int(offsets[i]), int(counts[i])
+		if o < 0 || c < 0 || o+c > len(d.data) {
+			return nil, errTIFFFormat
+		}
+		strip, err := tiffDecompress(compression, d.data[o:o+c], rows*rowBytes)
+		if err != nil {
+			return nil, err
+		}
+		if len(strip) < rows*rowBytes {
+			return nil, errTIFFFormat
+		}
+		strips = append(strips, strip[:rows*rowBytes])
+		n += rows * rowBytes
+	}
+	if n < size {
+		return nil, errTIFFFormat
+	}
+	//复制后再还原预测, 不修改输入数据
+	pix := make([]byte, 0, size)
+	for _, strip := range strips {
+		pix = append(pix, strip...)
+	}
+	if d.first(tiffPredictor, 1) == 2 {
+		tiffUndoPredictor(pix, rowBytes, spp, bps, d.bo)
+	}
+
+	rect := image.Rect(0, 0, w, h)
+	sample16 := func(i int) uint16 { return d.bo.Uint16(pix[i*2:]) }
+	switch {
+	case (photometric == 0 || photometric == 1) && bps == 16:
+		m := image.NewGray16(rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				v := sample16((y*rowBytes)/2 + x*spp)
+				if photometric == 0 {
+					v = 0xffff - v
+				}
+				m.SetGray16(x, y, color.Gray16{v})
+			}
+		}
+		return m, nil
+	case (photometric == 0 || photometric == 1) && bps <= 8:
+		m := image.NewGray(rect)
+		max := 1<<uint(bps) - 1
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				v := tiffBits(pix[y*rowBytes:], x*spp, bps)
+				g := uint8(v * 255 / max)
+				if photometric == 0 {
+					g = 255 - g
+				}
+				m.Pix[y*m.Stride+x] = g
+			}
+		}
+		return m, nil
+	case photometric == 3 && bps <= 8:
+		cm := d.entries[tiffColorMap]
+		n := 1 << uint(bps)
+		if len(cm) < 3*n {
+			return nil, errTIFFFormat
+		}
+		pal := make(color.Palette, n)
+		for i := range pal {
+			pal[i] = color.RGBA64{uint16(cm[i]), uint16(cm[n+i]), uint16(cm[2*n+i]), 0xffff}
+		}
+		m := image.NewPaletted(rect, pal)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				m.Pix[y*m.Stride+x] = uint8(tiffBits(pix[y*rowBytes:], x*spp, bps))
+			}
+		}
+		return m, nil
+	case photometric == 2 && spp >= 3 && (bps == 8 || bps == 16):
+		//ExtraSamples为1表示预乘alpha, 其它按非预乘处理
+		hasAlpha := spp >= 4
+		premul := hasAlpha && d.first(tiffExtraSamples, 0) == 1
+		m := image.NewNRGBA64(rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				var c [4]uint16
+				c[3] = 0xffff
+				for s := 0; s < 3 || (s == 3 && hasAlpha); s++ {
+					if bps == 8 {
+						v := pix[y*rowBytes+x*spp+s]
+						c[s] = uint16(v)<<8 | uint16(v)
+					} else {
+						c[s] = sample16((y*rowBytes)/2 + x*spp + s)
+					}
+				}
+				if premul && c[3] != 0 {
+					for s := 0; s < 3; s++ {
+						c[s] = uint16(minInt(0xffff, int(c[s])*0xffff/int(c[3])))
+					}
+				}
+				m.SetNRGBA64(x, y, color.NRGBA64{c[0], c[1], c[2], c[3]})
+			}
+		}
+		return m, nil
+	case photometric == 5 && spp >= 4 && bps == 8:
+		m := image.NewCMYK(rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				copy(m.Pix[y*m.Stride+x*4:y*m.Stride+x*4+4], pix[y*rowBytes+x*spp:])
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("tiff: unsupported photometric %d with %d samples of %d bits", photometric, spp, bps)
+}
+
+//tiffBits 取出row中第i个bps位的采样值
+func tiffBits(row []byte, i, bps int) int {
+	if bps == 8 {
+		return int(row[i])
+	}
+	bit := i * bps
+	b := row[bit/8]
+	shift := uint(8 - bps - bit%8)
+	return int(b>>shift) & (1<<uint(bps) - 1)
+}
+
+//tiffUndoPredictor 还原水平差分预测
+func tiffUndoPredictor(strip []byte, rowBytes, spp, bps int, bo binary.ByteOrder) {
+	for r := 0; r+rowBytes <= len(strip); r += rowBytes {
+		row := strip[r : r+rowBytes]
+		switch bps {
+		case 8:
+			for i := spp; i < len(row); i++ {
+				row[i] += row[i-spp]
+			}
+		case 16:
+			for i := spp * 2; i+1 < len(row); i += 2 {
+				bo.PutUint16(row[i:], bo.Uint16(row[i:])+bo.Uint16(row[i-spp*2:]))
+			}
+		}
+	}
+}
+
+//tiffDecompress 解压条带, 最多输出limit字节
+func tiffDecompress(compression uint32, src []byte, limit int) ([]byte, error) {
+	switch compression {
+	case 1:
+		return src, nil
+	case 5:
+		return tiffLZW(src, limit)
+	case 8, 32946:
+		r, err := zlib.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(io.LimitReader(r, int64(limit)))
+	case 32773:
+		return packBits(src, limit)
+	}
+	return nil, fmt.Errorf("tiff: unsupported compression %d", compression)
+}
+
+//packBits 解码PackBits, 输出达到limit字节时停止
+func packBits(src []byte, limit int) ([]byte, error) {
+	var out []byte
+	for i := 0; i < len(src) && len(out) < limit; {
+		n := int(int8(src[i]))
+		i++
+		switch {
+		case n >= 0:
+			if i+n+1 > len(src) {
+				return nil, errTIFFFormat
+			}
+			out = append(out, src[i:i+n+1]...)
+			i += n + 1
+		case n != -128:
+			if i >= len(src) {
+				return nil, errTIFFFormat
+			}
+			for k := 0; k < 1-n; k++ {
+				out = append(out, src[i])
+			}
+			i++
+		}
+	}
+	return out, nil
+}
+
+//tiffLZW 解码TIFF的LZW: 高位在前, 码宽提前一个码增加. 输出达到limit字节时停止
+func tiffLZW(src []byte, limit int) ([]byte, error) {
+	const (
+		clear = 256
+		eoi   = 257
+	)
+	var (
+		out   []byte
+		table = make([][]byte, 4096)
+		next  = 258
+		width = 9
+		prev  []byte
+		buf   uint32
+		nbits uint
+		pos   int
+	)
+	for i := 0; i < 256; i++ {
+		table[i] = []byte{byte(i)}
+	}
+	for len(out) < limit {
+		for nbits < uint(width) && pos < len(src) {
+			buf = buf<<8 | uint32(src[pos])
+			pos++
+			nbits += 8
+		}
+		if nbits < uint(width) {
+			return out, nil
+		}
+		code := int(buf>>(nbits-uint(width))) & (1<<uint(width) - 1)
+		nbits -= uint(width)
+		if code == clear {
+			next, width, prev = 258, 9, nil
+			continue
+		}
+		if code == eoi {
+			return out, nil
+		}
+		var entry []byte
+		switch {
+		case code < next && table[code] != nil:
+			entry = table[code]
+		case code == next && prev != nil:
+			entry = append(prev[:len(prev):len(prev)], prev[0])
+		default:
+			return nil, errors.New("tiff: invalid LZW code")
+		}
+		out = append(out, entry...)
+		if prev != nil && next < 4096 {
+			table[next] = append(prev[:len(prev):len(prev)], entry[0])
+			next++
+		}
+		prev = entry
+		if next >= 1<<uint(width)-1 && width < 12 {
+			width++
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,118 @@
+/*
+* File Name:	tiff_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+//tiffWithEntries 生成只有一页的TIFF, 条带数据为strip, entries为{标签, 类型, 值}, 条带偏移自动填写
+func tiffWithEntries(entries [][3]uint32, strip []byte) []byte {
+	le := binary.LittleEndian
+	buf := []byte("II*\x00\x08\x00\x00\x00")
+	entries = append(entries, [3]uint32{tiffStripOffsets, 4, uint32(8 + 2 + 12*(len(entries)+1) + 4)})
+	ifd := make([]byte, 2+12*len(entries)+4)
+	le.PutUint16(ifd, uint16(len(entries)))
+	for i, e := range entries {
+		d := ifd[2+12*i:]
+		le.PutUint16(d, uint16(e[0]))
+		le.PutUint16(d[2:], uint16(e[1]))
+		le.PutUint32(d[4:], 1)
+		if e[1] == 3 {
+			le.PutUint16(d[8:], uint16(e[2]))
+		} else {
+			le.PutUint32(d[8:], e[2])
+		}
+	}
+	return append(append(buf, ifd...), strip...)
+}
+
+func TestDecodeTIFFMalformed(t *testing.T) {
+	strip := make([]byte, 16)
+	for _, c := range []struct {
+		name           string
+		w, h, spp, bps uint32
+		stripBytes     uint32
+	}{
+		//约140字节的文件声明32768x32768, 8个16位采样, 解码前不能按声明的尺寸分配内存
+		{"huge", 32768, 32768, 8, 16, 16},
+		{"huge rgba", 32768, 32768, 4, 16, 16},
+		{"strips too short", 4096, 4096, 3, 8, 16},
+		{"zero bits per sample", 4, 4, 1, 0, 16},
+		{"odd bits per sample", 4, 4, 1, 3, 16},
+		{"zero samples per pixel", 4, 4, 0, 8, 16},
+	} {
+		data := tiffWithEntries([][3]uint32{
+			{tiffImageWidth, 4, c.w},
+			{tiffImageLength, 4, c.h},
+			{tiffBitsPerSample, 3, c.bps},
+			{tiffCompression, 3, 1},
+			{tiffPhotometric, 3, 1},
+			{tiffSamplesPerPixel, 3, c.spp},
+			{tiffStripByteCounts, 4, c.stripBytes},
+		}, strip)
+		if _, err := decodeTIFF(data); err != errTIFFFormat {
+			t.Errorf("decodeTIFF(%s) = %v, want %v\n", c.name, err, errTIFFFormat)
+		}
+	}
+
+	//正常的4x4灰度图
+	gray := tiffWithEntries([][3]uint32{
+		{tiffImageWidth, 4, 4},
+		{tiffImageLength, 4, 4},
+		{tiffBitsPerSample, 3, 8},
+		{tiffCompression, 3, 1},
+		{tiffPhotometric, 3, 1},
+		{tiffStripByteCounts, 4, 16},
+	}, strip)
+	if _, err := decodeTIFF(gray); err != nil {
+		t.Errorf("decodeTIFF(gray) failed: %s\n", err)
+	}
+}
+
+//tiffWithCounts 生成只有一个IFD的TIFF, 每个标签都是SHORT类型, 声明cnt个值, 值都指向文件开头
+func tiffWithCounts(tags []uint16, cnt uint32, size int) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, size)
+	copy(data, "II*\x00\x08\x00\x00\x00")
+	le.PutUint16(data[8:], uint16(len(tags)))
+	for i, tag := range tags {
+		e := data[10+12*i:]
+		le.PutUint16(e, tag)
+		le.PutUint16(e[2:], 3)
+		le.PutUint32(e[4:], cnt)
+		le.PutUint32(e[8:], 0)
+	}
+	return data
+}
+
+func TestParseTIFFHugeCounts(t *testing.T) {
+	//4KB的文件里300个标签都声明了整个文件长度的值, 不能为每个标签都分配
+	tags := make([]uint16, 300)
+	for i := range tags {
+		tags[i] = tiffColorMap
+	}
+	data := tiffWithCounts(tags, 2048, 4096)
+	if _, err := parseTIFF(data); err != errTIFFFormat {
+		t.Errorf("parseTIFF(huge counts) = %v, want %v\n", err, errTIFFFormat)
+	}
+	if n := tiffPageCount(data); n > 1 {
+		t.Errorf("tiffPageCount(huge counts) = %d\n", n)
+	}
+	if _, err := splitDocument(ImageBytes(data)); err == nil {
+		t.Errorf("splitDocument accepted huge counts\n")
+	}
+
+	//不使用的标签不读取值
+	for i := range tags {
+		tags[i] = 700
+	}
+	if _, err := parseTIFF(tiffWithCounts(tags, 2048, 4096)); err != nil {
+		t.Errorf("parseTIFF(unused tags) failed: %s\n", err)
+	}
+}
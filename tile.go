@@ -0,0 +1,258 @@
+/*
+* File Name:	tile.go
+* Description:  大图分块OCR: 重叠分块并发识别, 映射回整页坐标并合并被分块边界切开的文字
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"image"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	//DefaultTileSize 分块的默认边长
+	DefaultTileSize = 1024
+	//DefaultTileOverlap 相邻分块默认的重叠宽度, 应大于一行文字的高度
+	DefaultTileOverlap = 200
+	//DefaultTileMinOverlap 判定为同一文字框的默认重叠比例, 即交集面积与较小框面积之比
+	DefaultTileMinOverlap = 0.5
+	//DefaultTileMinSimilarity 判定为同一文字的默认文本相似度
+	DefaultTileMinSimilarity = 0.6
+)
+
+//TileOptions 分块OCR选项, 值为0的项使用默认值
+type TileOptions struct {
+	TileSize      int     //分块边长
+	Overlap       int     //相邻分块的重叠宽度, 不超过TileSize的一半
+	Concurrency   int     //同时识别的分块数, 默认DefaultDocumentConcurrency
+	MinOverlap    float64 //判定为同一文字框的重叠比例
+	MinSimilarity float64 //判定为同一文字的文本相似度(0~1), 一个文本包含另一个时视为1
+	Seq           string  //序列号
+}
+
+func (o TileOptions) withDefaults() TileOptions {
+	if o.TileSize <= 0 {
+		o.TileSize = DefaultTileSize
+	}
+	if o.Overlap <= 0 {
+		o.Overlap = DefaultTileOverlap
+	}
+	if o.Overlap > o.TileSize/2 {
+		o.Overlap = o.TileSize / 2
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultDocumentConcurrency
+	}
+	if o.MinOverlap <= 0 {
+		o.MinOverlap = DefaultTileMinOverlap
+	}
+	if o.MinSimilarity <= 0 {
+		o.MinSimilarity = DefaultTileMinSimilarity
+	}
+	return o
+}
+
+//tileStarts 长度n按size和overlap分块的起点, 最后一块与末端对齐
+func tileStarts(n, size, overlap int) []int {
+	if n <= size {
+		return []int{0}
+	}
+	step := size - overlap
+	var starts []int
+	for s := 0; ; s += step {
+		if s+size >= n {
+			starts = append(starts, n-size)
+			return starts
+		}
+		starts = append(starts, s)
+	}
+}
+
+//tileRects 把w*h按行优先分成重叠的块
+func tileRects(w, h, size, overlap int) []image.Rectangle {
+	var rects []image.Rectangle
+	for _, y := range tileStarts(h, size, overlap) {
+		for _, x := range tileStarts(w, size, overlap) {
+			rects = append(rects, image.Rect(x, y, minInt(x+size, w), minInt(y+size, h)))
+		}
+	}
+	return rects
+}
+
+//GeneralOcrTiled 分块识别大图, 适合长票据、海报和A3扫描件中的小字.
+//图片在本地解码并按EXIF摆正, 不超过一块时直接调用GeneralOcrRequest.
+//分块时结果坐标相对于摆正后的整页, Transform记录原图到整页的变换, 按从上到下、从左到右排序
+func (y *Youtu) GeneralOcrTiled(img Image, o TileOptions) (rsp GeneralOcrRsp, err error) {
+	o = o.withDefaults()
+	m, t, err := y.decodeImage("generalocr", img)
+	if err != nil {
+		return
+	}
+	w, h := m.Bounds().Dx(), m.Bounds().Dy()
+	if w <= o.TileSize && h <= o.TileSize {
+		return y.GeneralOcrRequest(GeneralOcrRequest{Image: img, Seq: o.Seq})
+	}
+	if rsp, err = y.ocrTiles(m, o); err == nil && !t.IsIdentity() {
+		rsp.Transform = &t
+	}
+	return
+}
+
+//ocrTiles 并发识别m的各块并合并结果
+func (y *Youtu) ocrTiles(m *image.RGBA, o TileOptions) (rsp GeneralOcrRsp, err error) {
+	rects := tileRects(m.Bounds().Dx(), m.Bounds().Dy(), o.TileSize, o.Overlap)
+	results := make([]GeneralOcrRsp, len(rects))
+	errs := make([]error, len(rects))
+	sem := make(chan struct{}, o.Concurrency)
+	var wg sync.WaitGroup
+	for i, r := range rects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, r image.Rectangle) {
+			defer func() { <-sem; wg.Done() }()
+			//分块图片记录整页到分块的平移, Original映射回整页坐标
+			tile := ImageJPEG(m.SubImage(r), DefaultNormalizeQuality).
+				WithTransform(TranslateTransform(float64(-r.Min.X), float64(-r.Min.Y)))
+			results[i], errs[i] = y.GeneralOcrRequest(GeneralOcrRequest{Image: tile, Seq: o.Seq})
+		}(i, r)
+	}
+	wg.Wait()
+
+	var items []ItemContent
+	var tiles []int
+	for i, r := range results {
+		if errs[i] != nil {
+			return r, errs[i]
+		}
+		if r.ErrorCode != 0 {
+			return r, nil
+		}
+		rsp.SessionId = r.SessionId
+		for _, it := range r.Original().Items {
+			items = append(items, it)
+			tiles = append(tiles, i)
+		}
+	}
+	rsp.Items = mergeTileItems(items, tiles, o)
+	return
+}
+
+//mergeTileItems 去掉不同分块中重复识别的文字, 保留文本较长(较完整)的一个
+func mergeTileItems(items []ItemContent, tiles []int, o TileOptions) []ItemContent {
+	dropped := make([]bool, len(items))
+	for i := range items {
+		for j := i + 1; j < len(items) && !dropped[i]; j++ {
+			if dropped[j] || tiles[i] == tiles[j] || !sameTileItem(items[i], items[j], o) {
+				continue
+			}
+			if betterTileItem(items[j], items[i]) {
+				dropped[i] = true
+			} else {
+				dropped[j] = true
+			}
+		}
+	}
+	var kept []ItemContent
+	for i, it := range items {
+		if !dropped[i] {
+			kept = append(kept, it)
+		}
+	}
+	sortTileItems(kept)
+	return kept
+}
+
+//sortTileItems 按阅读顺序排序: 先按中心的纵坐标把文字框分成行, 行内从左到右
+func sortTileItems(items []ItemContent) {
+	rects := make([]Rect, len(items))
+	for i, it := range items {
+		rects[i] = it.Rect()
+	}
+	byY := make([]int, len(items))
+	for i := range byY {
+		byY[i] = i
+	}
+	sort.SliceStable(byY, func(i, j int) bool {
+		return rects[byY[i]].Center().Y < rects[byY[j]].Center().Y
+	})
+	//中心在行首文字框高度范围内的归入该行
+	rows := make([]int, len(items))
+	var first Rect
+	for k, i := range byY {
+		if c := rects[i].Center().Y; k == 0 || c < first.Min.Y || c >= first.Max.Y {
+			if k > 0 {
+				rows[i] = rows[byY[k-1]] + 1
+			}
+			first = rects[i]
+			continue
+		}
+		rows[i] = rows[byY[k-1]]
+	}
+	sort.SliceStable(byY, func(i, j int) bool {
+		a, b := byY[i], byY[j]
+		if rows[a] != rows[b] {
+			return rows[a] < rows[b]
+		}
+		return rects[a].Min.X < rects[b].Min.X
+	})
+	sorted := make([]ItemContent, len(items))
+	for k, i := range byY {
+		sorted[k] = items[i]
+	}
+	copy(items, sorted)
+}
+
+//sameTileItem 两个文字框是否为同一处文字
+func sameTileItem(a, b ItemContent, o TileOptions) bool {
+	ra, rb := a.Rect(), b.Rect()
+	small := ra.Area()
+	if rb.Area() < small {
+		small = rb.Area()
+	}
+	if small <= 0 || ra.Intersect(rb).Area()/small < o.MinOverlap {
+		return false
+	}
+	return textSimilarity(a.Itemstring, b.Itemstring) >= o.MinSimilarity
+}
+
+//betterTileItem a是否比b完整
+func betterTileItem(a, b ItemContent) bool {
+	la, lb := len([]rune(a.Itemstring)), len([]rune(b.Itemstring))
+	if la != lb {
+		return la > lb
+	}
+	return a.Itemconf > b.Itemconf
+}
+
+//textSimilarity 按编辑距离计算的文本相似度, 一个包含另一个时为1
+func textSimilarity(a, b string) float64 {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(maxInt(len(ra), len(rb)))
+}
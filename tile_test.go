@@ -0,0 +1,109 @@
+/*
+* File Name:	tile_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTileStarts(t *testing.T) {
+	for _, c := range []struct {
+		n, size, overlap int
+		want             []int
+	}{
+		{500, 1000, 200, []int{0}},
+		{1800, 1000, 200, []int{0, 800}},
+		{2600, 1000, 200, []int{0, 800, 1600}},
+		{2500, 1000, 200, []int{0, 800, 1500}},
+	} {
+		if got := tileStarts(c.n, c.size, c.overlap); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("tileStarts(%d, %d, %d) = %v, want %v\n", c.n, c.size, c.overlap, got, c.want)
+		}
+	}
+	if s := textSimilarity("Hello wor", "Hello world"); s != 1 {
+		t.Errorf("textSimilarity of a prefix = %v\n", s)
+	}
+	if s := textSimilarity("abcd", "abce"); s != 0.75 {
+		t.Errorf("textSimilarity(abcd, abce) = %v\n", s)
+	}
+}
+
+func TestSortTileItems(t *testing.T) {
+	item := func(s string, x, y int32) ItemContent {
+		return ItemContent{Itemstring: s, Itemcoord: Coordinate{X: x, Y: y, Width: 40, Height: 20}}
+	}
+	//a和b、b和c的中心互在对方高度范围内, a和c不在, 任何输入顺序的结果都应相同
+	a, b, c := item("a", 100, 0), item("b", 50, 5), item("c", 0, 14)
+	want := []string{"b", "a", "c"}
+	for _, in := range [][]ItemContent{{a, b, c}, {a, c, b}, {b, a, c}, {b, c, a}, {c, a, b}, {c, b, a}} {
+		sortTileItems(in)
+		var got []string
+		for _, it := range in {
+			got = append(got, it.Itemstring)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("sortTileItems = %v, want %v\n", got, want)
+		}
+	}
+}
+
+func TestGeneralOcrTiled(t *testing.T) {
+	//左半黑右半白, 服务器按分块左上角的颜色区分分块
+	m := image.NewRGBA(image.Rect(0, 0, 1800, 600))
+	draw.Draw(m, m.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(m, image.Rect(800, 0, 1800, 600), image.NewUniform(color.White), image.Point{}, draw.Src)
+	item := func(s string, x, w int32) ItemContent {
+		return ItemContent{Itemstring: s, Itemcoord: Coordinate{X: x, Y: 100, Width: w, Height: 30}}
+	}
+	var sizes []image.Point
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GeneralOcrReq
+		json.NewDecoder(r.Body).Decode(&req)
+		data, _ := base64.StdEncoding.DecodeString(req.Image)
+		tile, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			w.Write([]byte(`{"errorcode":-1}`))
+			return
+		}
+		sizes = append(sizes, tile.Bounds().Size())
+		rsp := GeneralOcrRsp{Items: []ItemContent{item("right", 500, 80), item("Hello world", 100, 120)}}
+		if c, _, _, _ := tile.At(0, 0).RGBA(); c < 0x8000 {
+			rsp.Items = []ItemContent{item("Hello wor", 900, 100), item("left", 100, 60)}
+		}
+		json.NewEncoder(w).Encode(rsp)
+	}))
+	defer srv.Close()
+	y := Init(AppSign{}, srv.URL)
+
+	rsp, err := y.GeneralOcrTiled(ImagePNG(m), TileOptions{TileSize: 1000, Overlap: 200, Concurrency: 1})
+	if err != nil {
+		t.Errorf("GeneralOcrTiled failed: %s\n", err)
+		return
+	}
+	if len(sizes) != 2 || sizes[0] != image.Pt(1000, 600) || sizes[1] != image.Pt(1000, 600) {
+		t.Errorf("tiles sent = %v\n", sizes)
+	}
+	want := []ItemContent{item("left", 100, 60), item("Hello world", 900, 120), item("right", 1300, 80)}
+	if !reflect.DeepEqual(rsp.Items, want) || rsp.Transform != nil {
+		t.Errorf("GeneralOcrTiled items = %+v\n", rsp.Items)
+	}
+
+	//不超过一块时直接识别
+	sizes = nil
+	if _, err := y.GeneralOcrTiled(ImagePNG(m), TileOptions{TileSize: 2000}); err != nil || len(sizes) != 1 || sizes[0] != image.Pt(1800, 600) {
+		t.Errorf("GeneralOcrTiled with one tile = %v, %v\n", err, sizes)
+	}
+}
@@ -0,0 +1,388 @@
+/*
+* File Name:	transform.go
+* Description:  原图到发送图片的坐标变换, 以及把结果坐标映射回原图
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"math"
+)
+
+//Transform 把原图坐标映射到发送给服务器的图片坐标的3x3齐次矩阵(按行存储),
+//可以表示缩放、平移、旋转、翻转和透视变换. 坐标是连续的像素边界坐标,
+//原图左上角为(0, 0), 右下角为(宽, 高)
+type Transform struct {
+	m [9]float64
+}
+
+//IdentityTransform 不变换
+func IdentityTransform() Transform {
+	return Transform{[9]float64{1, 0, 0, 0, 1, 0, 0, 0, 1}}
+}
+
+//NewTransform 由按行存储的3x3矩阵创建变换
+func NewTransform(m [9]float64) Transform {
+	return Transform{m}
+}
+
+//ScaleTransform 缩放
+func ScaleTransform(sx, sy float64) Transform {
+	return Transform{[9]float64{sx, 0, 0, 0, sy, 0, 0, 0, 1}}
+}
+
+//TranslateTransform 平移, 裁剪时dx, dy为裁剪区域左上角坐标的相反数
+func TranslateTransform(dx, dy float64) Transform {
+	return Transform{[9]float64{1, 0, dx, 0, 1, dy, 0, 0, 1}}
+}
+
+//OrientationTransform 宽w高h的图片按EXIF方向值(1~8)摆正时的变换
+func OrientationTransform(orientation, w, h int) Transform {
+	fw, fh := float64(w), float64(h)
+	switch orientation {
+	case 2:
+		return Transform{[9]float64{-1, 0, fw, 0, 1, 0, 0, 0, 1}}
+	case 3:
+		return Transform{[9]float64{-1, 0, fw, 0, -1, fh, 0, 0, 1}}
+	case 4:
+		return Transform{[9]float64{1, 0, 0, 0, -1, fh, 0, 0, 1}}
+	case 5:
+		return Transform{[9]float64{0, 1, 0, 1, 0, 0, 0, 0, 1}}
+	case 6:
+		return Transform{[9]float64{0, -1, fh, 1, 0, 0, 0, 0, 1}}
+	case 7:
+		return Transform{[9]float64{0, -1, fh, -1, 0, fw, 0, 0, 1}}
+	case 8:
+		return Transform{[9]float64{0, 1, 0, -1, 0, fw, 0, 0, 1}}
+	}
+	return IdentityTransform()
+}
+
+//Matrix 返回按行存储的矩阵
+func (t Transform) Matrix() [9]float64 {
+	return t.m
+}
+
+//IsIdentity 是否为不变换
+func (t Transform) IsIdentity() bool {
+	return t.m == IdentityTransform().m
+}
+
+//Then 先做t再做u的变换
+func (t Transform) Then(u Transform) Transform {
+	var r Transform
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r.m[i*3+j] += u.m[i*3+k] * t.m[k*3+j]
+			}
+		}
+	}
+	return r
+}
+
+//Inverse 逆变换, 矩阵不可逆时ok为false
+func (t Transform) Inverse() (inv Transform, ok bool) {
+	m := t.m
+	c := [9]float64{
+		m[4]*m[8] - m[5]*m[7], m[2]*m[7] - m[1]*m[8], m[1]*m[5] - m[2]*m[4],
+		m[5]*m[6] - m[3]*m[8], m[0]*m[8] - m[2]*m[6], m[2]*m[3] - m[0]*m[5],
+		m[3]*m[7] - m[4]*m[6], m[1]*m[6] - m[0]*m[7], m[0]*m[4] - m[1]*m[3],
+	}
+	det := m[0]*c[0] + m[1]*c[3] + m[2]*c[6]
+	if det == 0 || math.IsNaN(det) {
+		return
+	}
+	for i := range c {
+		inv.m[i] = c[i] / det
+	}
+	return inv, true
+}
+
+//Apply 变换点(x, y)
+func (t Transform) Apply(x, y float64) (float64, float64) {
+	m := t.m
+	w := m[6]*x + m[7]*y + m[8]
+	if w == 0 {
+		w = 1e-12
+	}
+	return (m[0]*x + m[1]*y + m[2]) / w, (m[3]*x + m[4]*y + m[5]) / w
+}
+
+//ApplyRect 变换矩形, 返回四个角变换后的外接矩形
+func (t Transform) ApplyRect(x, y, w, h float64) (rx, ry, rw, rh float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range [4][2]float64{{x, y}, {x + w, y}, {x, y + h}, {x + w, y + h}} {
+		px, py := t.Apply(c[0], c[1])
+		minX, maxX = math.Min(minX, px), math.Max(maxX, px)
+		minY, maxY = math.Min(minY, py), math.Max(maxY, py)
+	}
+	return minX, minY, maxX - minX, maxY - minY
+}
+
+//applyAngle 变换点(x, y)处角度为deg(度, 顺时针为正)的方向, 返回新角度和是否翻转
+func (t Transform) applyAngle(x, y, deg float64) (float64, bool) {
+	const eps = 1.0
+	r := deg * math.Pi / 180
+	x0, y0 := t.Apply(x, y)
+	x1, y1 := t.Apply(x+eps*math.Cos(r), y+eps*math.Sin(r))
+	a := math.Atan2(y1-y0, x1-x0) * 180 / math.Pi
+	m := t.m
+	mirrored := m[0]*m[4]-m[1]*m[3] < 0
+	return a, mirrored
+}
+
+//thenTransform 在t之后接上u, 都是不变换时返回nil
+func thenTransform(t *Transform, u Transform) *Transform {
+	if t == nil {
+		if u.IsIdentity() {
+			return nil
+		}
+		return &u
+	}
+	r := t.Then(u)
+	return &r
+}
+
+func round32(v float64) int32 {
+	return int32(math.Round(v))
+}
+
+func roundInt(v float64) int {
+	return int(math.Round(v))
+}
+
+//mapSize 发送图片的宽高映射回原图后的宽高
+func mapSize(inv Transform, w, h float64) (float64, float64) {
+	_, _, rw, rh := inv.ApplyRect(0, 0, w, h)
+	return rw, rh
+}
+
+func (f Face) mapped(inv Transform) Face {
+	x, y, w, h := inv.ApplyRect(float64(f.X), float64(f.Y), float64(f.Width), float64(f.Height))
+	cx, cy := float64(f.X)+float64(f.Width)/2, float64(f.Y)+float64(f.Height)/2
+	roll, mirrored := inv.applyAngle(cx, cy, float64(f.Roll))
+	f.X, f.Y = round32(x), round32(y)
+	f.Width, f.Height = float32(w), float32(h)
+	f.Roll = round32(roll)
+	if mirrored {
+		f.Yaw = -f.Yaw
+	}
+	return f
+}
+
+func mapPos(inv Transform, ps []pos) []pos {
+	if ps == nil {
+		return nil
+	}
+	out := make([]pos, len(ps))
+	for i, p := range ps {
+		x, y := inv.Apply(float64(p.X), float64(p.Y))
+		out[i] = pos{X: roundInt(x), Y: roundInt(y)}
+	}
+	return out
+}
+
+func (s FaceShape) mapped(inv Transform) FaceShape {
+	return FaceShape{
+		FaceProfile:  mapPos(inv, s.FaceProfile),
+		LeftEye:      mapPos(inv, s.LeftEye),
+		RightEye:     mapPos(inv, s.RightEye),
+		LeftEyebrow:  mapPos(inv, s.LeftEyebrow),
+		RightEyebrow: mapPos(inv, s.RightEyebrow),
+		Mouth:        mapPos(inv, s.Mouth),
+		Nose:         mapPos(inv, s.Nose),
+	}
+}
+
+func (r FaceRectItem) mapped(inv Transform) FaceRectItem {
+	x, y, w, h := inv.ApplyRect(float64(r.X), float64(r.Y), float64(r.Width), float64(r.Height))
+	return FaceRectItem{X: roundInt(x), Y: roundInt(y), Width: roundInt(w), Height: roundInt(h)}
+}
+
+func (c Coordinate) mapped(inv Transform) Coordinate {
+	x, y, w, h := inv.ApplyRect(float64(c.X), float64(c.Y), float64(c.Width), float64(c.Height))
+	return Coordinate{X: round32(x), Y: round32(y), Width: round32(w), Height: round32(h)}
+}
+
+func (c CarCoordinate) mapped(inv Transform) CarCoordinate {
+	x, y, w, h := inv.ApplyRect(float64(c.X), float64(c.Y), float64(c.Width), float64(c.Height))
+	return CarCoordinate{X: float32(x), Y: float32(y), Width: float32(w), Height: float32(h)}
+}
+
+func (it ItemContent) mapped(inv Transform) ItemContent {
+	it.Itemcoord = it.Itemcoord.mapped(inv)
+	if it.Coords != nil {
+		coords := make([]Coordinate, len(it.Coords))
+		for i, c := range it.Coords {
+			coords[i] = c.mapped(inv)
+		}
+		it.Coords = coords
+	}
+	return it
+}
+
+//MapToOriginal 把结果中的人脸框和图片尺寸映射回原图坐标, t是原图到发送图片的变换.
+//旋转或透视时人脸框取四个角的外接矩形, Roll按变换的旋转角度调整, 翻转时Yaw取反
+func (rsp DetectFaceRsp) MapToOriginal(t Transform) DetectFaceRsp {
+	inv, ok := t.Inverse()
+	if !ok {
+		return rsp
+	}
+	w, h := mapSize(inv, float64(rsp.ImageWidth), float64(rsp.ImageHeight))
+	rsp.ImageWidth, rsp.ImageHeight = round32(w), round32(h)
+	faces := make([]Face, len(rsp.Face))
+	for i, f := range rsp.Face {
+		faces[i] = f.mapped(inv)
+	}
+	rsp.Face = faces
+	rsp.Transform = nil
+	return rsp
+}
+
+//Original 按请求时记录的Transform映射回原图坐标, 没有变换时原样返回
+func (rsp DetectFaceRsp) Original() DetectFaceRsp {
+	if rsp.Transform == nil {
+		return rsp
+	}
+	return rsp.MapToOriginal(*rsp.Transform)
+}
+
+//MapToOriginal 把五官定位点和图片尺寸映射回原图坐标, t是原图到发送图片的变换
+func (rsp FaceShapeRsp) MapToOriginal(t Transform) FaceShapeRsp {
+	inv, ok := t.Inverse()
+	if !ok {
+		return rsp
+	}
+	w, h := mapSize(inv, float64(rsp.ImageWidth), float64(rsp.ImageHeight))
+	rsp.ImageWidth, rsp.ImageHeight = roundInt(w), roundInt(h)
+	shapes := make([]FaceShape, len(rsp.FaceShape))
+	for i, s := range rsp.FaceShape {
+		shapes[i] = s.mapped(inv)
+	}
+	rsp.FaceShape = shapes
+	rsp.Transform = nil
+	return rsp
+}
+
+//Original 按请求时记录的Transform映射回原图坐标, 没有变换时原样返回
+func (rsp FaceShapeRsp) Original() FaceShapeRsp {
+	if rsp.Transform == nil {
+		return rsp
+	}
+	return rsp.MapToOriginal(*rsp.Transform)
+}
+
+//MapToOriginal 把人脸框映射回原图坐标, t是原图到发送图片的变换
+func (rsp MultiFaceIdentifyRsp) MapToOriginal(t Transform) MultiFaceIdentifyRsp {
+	inv, ok := t.Inverse()
+	if !ok {
+		return rsp
+	}
+	results := make([]MultiIdentifyItem, len(rsp.Results))
+	for i, r := range rsp.Results {
+		r.FaceRect = r.FaceRect.mapped(inv)
+		results[i] = r
+	}
+	rsp.Results = results
+	rsp.Transform = nil
+	return rsp
+}
+
+//Original 按请求时记录的Transform映射回原图坐标, 没有变换时原样返回
+func (rsp MultiFaceIdentifyRsp) Original() MultiFaceIdentifyRsp {
+	if rsp.Transform == nil {
+		return rsp
+	}
+	return rsp.MapToOriginal(*rsp.Transform)
+}
+
+//MapToOriginal 把文字框Itemcoord和Coords映射回原图坐标, t是原图到发送图片的变换
+func (rsp GeneralOcrRsp) MapToOriginal(t Transform) GeneralOcrRsp {
+	inv, ok := t.Inverse()
+	if !ok {
+		return rsp
+	}
+	rsp.Items = mapItems(inv, rsp.Items)
+	rsp.Transform = nil
+	return rsp
+}
+
+//Original 按请求时记录的Transform映射回原图坐标, 没有变换时原样返回
+func (rsp GeneralOcrRsp) Original() GeneralOcrRsp {
+	if rsp.Transform == nil {
+		return rsp
+	}
+	return rsp.MapToOriginal(*rsp.Transform)
+}
+
+//mapItems 用逆变换inv映射识别项的坐标
+func mapItems(inv Transform, items []ItemContent) []ItemContent {
+	out := make([]ItemContent, len(items))
+	for i, it := range items {
+		out[i] = it.mapped(inv)
+	}
+	return out
+}
+
+//MapToOriginal 把识别项坐标映射回原图坐标, t是原图到发送图片的变换
+func (rsp DriverlicenseOcrRsp) MapToOriginal(t Transform) DriverlicenseOcrRsp {
+	if inv, ok := t.Inverse(); ok {
+		rsp.Items = mapItems(inv, rsp.Items)
+		rsp.Transform = nil
+	}
+	return rsp
+}
+
+//Original 按请求时记录的Transform映射回原图坐标, 没有变换时原样返回
+func (rsp DriverlicenseOcrRsp) Original() DriverlicenseOcrRsp {
+	if rsp.Transform == nil {
+		return rsp
+	}
+	return rsp.MapToOriginal(*rsp.Transform)
+}
+
+//MapToOriginal 把识别项坐标映射回原图坐标, t是原图到发送图片的变换
+func (rsp BCOcrRsp) MapToOriginal(t Transform) BCOcrRsp {
+	if inv, ok := t.Inverse(); ok {
+		rsp.Items = mapItems(inv, rsp.Items)
+		rsp.Transform = nil
+	}
+	return rsp
+}
+
+//Original 按请求时记录的Transform映射回原图坐标, 没有变换时原样返回
+func (rsp BCOcrRsp) Original() BCOcrRsp {
+	if rsp.Transform == nil {
+		return rsp
+	}
+	return rsp.MapToOriginal(*rsp.Transform)
+}
+
+//MapToOriginal 把车辆框和车标框映射回原图坐标, t是原图到发送图片的变换
+func (rsp CarClassifyRsp) MapToOriginal(t Transform) CarClassifyRsp {
+	inv, ok := t.Inverse()
+	if !ok {
+		return rsp
+	}
+	rsp.CarCoord = rsp.CarCoord.mapped(inv)
+	if rsp.LogoCoord != nil {
+		logos := make([]CarCoordinate, len(rsp.LogoCoord))
+		for i, c := range rsp.LogoCoord {
+			logos[i] = c.mapped(inv)
+		}
+		rsp.LogoCoord = logos
+	}
+	rsp.Transform = nil
+	return rsp
+}
+
+//Original 按请求时记录的Transform映射回原图坐标, 没有变换时原样返回
+func (rsp CarClassifyRsp) Original() CarClassifyRsp {
+	if rsp.Transform == nil {
+		return rsp
+	}
+	return rsp.MapToOriginal(*rsp.Transform)
+}
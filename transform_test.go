@@ -0,0 +1,139 @@
+/*
+* File Name:	transform_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransformInverse(t *testing.T) {
+	tr := OrientationTransform(6, 80, 40).Then(ScaleTransform(0.5, 0.5)).Then(TranslateTransform(-3, 7))
+	inv, ok := tr.Inverse()
+	if !ok {
+		t.Errorf("Inverse failed\n")
+		return
+	}
+	for _, p := range [][2]float64{{0, 0}, {80, 40}, {13.5, 27}} {
+		x, y := inv.Apply(tr.Apply(p[0], p[1]))
+		if math.Abs(x-p[0]) > 1e-9 || math.Abs(y-p[1]) > 1e-9 {
+			t.Errorf("inverse of %v = (%g, %g)\n", p, x, y)
+		}
+	}
+	//原图右上角顺时针旋转后到右下角
+	if x, y := OrientationTransform(6, 80, 40).Apply(80, 0); x != 40 || y != 80 {
+		t.Errorf("orientation 6 maps (80, 0) to (%g, %g), want (40, 80)\n", x, y)
+	}
+	if _, ok := NewTransform([9]float64{}).Inverse(); ok {
+		t.Errorf("singular matrix inverted\n")
+	}
+}
+
+func TestDetectFaceOriginal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"image_width":100,"image_height":200,"errorcode":0,
+			"face":[{"x":10,"y":20,"width":30,"height":40,"roll":0,"yaw":5}]}`))
+	}))
+	defer srv.Close()
+
+	//存储为400x200, EXIF方向为6, 摆正后200x400, 再缩小到100x200
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 400, 200)), nil)
+	data := withEXIF(buf.Bytes(), 6)
+
+	y := Init(AppSign{}, srv.URL)
+	y.SetPreprocess(&PreprocessOptions{DefaultMaxDimension: 200})
+	rsp, err := y.DetectFaceImage(ImageBytes(data), false)
+	if err != nil {
+		t.Errorf("DetectFaceImage failed: %s\n", err)
+		return
+	}
+	if rsp.Transform == nil {
+		t.Errorf("Transform not recorded\n")
+		return
+	}
+	o := rsp.Original()
+	if o.ImageWidth != 400 || o.ImageHeight != 200 {
+		t.Errorf("original image size = %dx%d, want 400x200\n", o.ImageWidth, o.ImageHeight)
+	}
+	f := o.Face[0]
+	if f.X != 40 || f.Y != 120 || f.Width != 80 || f.Height != 60 || f.Roll != -90 || f.Yaw != 5 {
+		t.Errorf("original face = %+v\n", f)
+	}
+	if rsp.Face[0].X != 10 {
+		t.Errorf("Original modified the response\n")
+	}
+}
+
+func TestGeneralOcrWithTransform(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errorcode":0,"items":[{"itemstring":"x",
+			"itemcoord":{"x":10,"y":10,"width":20,"height":5}}]}`))
+	}))
+	defer srv.Close()
+
+	y := Init(AppSign{}, srv.URL)
+	//调用者裁剪了原图(100, 50)开始的区域
+	img := ImageURL("http://example.com/crop.jpg").WithTransform(TranslateTransform(-100, -50))
+	rsp, err := y.GeneralOcrImage(img, "")
+	if err != nil {
+		t.Errorf("GeneralOcrImage failed: %s\n", err)
+		return
+	}
+	c := rsp.Original().Items[0].Itemcoord
+	if c != (Coordinate{X: 110, Y: 60, Width: 20, Height: 5}) {
+		t.Errorf("original itemcoord = %+v\n", c)
+	}
+}
+
+func TestPlateOcrWithTransform(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errorcode":0,"items":[{"itemstring":"x",
+			"itemcoord":{"x":10,"y":10,"width":20,"height":5}}]}`))
+	}))
+	defer srv.Close()
+
+	y := Init(AppSign{}, srv.URL)
+	img := ImageURL("http://example.com/crop.jpg").WithTransform(TranslateTransform(-100, -50))
+	rsp, err := y.PlateOcrImage(img, "")
+	if err != nil || rsp.Transform == nil {
+		t.Errorf("PlateOcrImage = %+v, %v\n", rsp, err)
+		return
+	}
+	c := rsp.Original().Items[0].Itemcoord
+	if c != (Coordinate{X: 110, Y: 60, Width: 20, Height: 5}) {
+		t.Errorf("original itemcoord = %+v\n", c)
+	}
+}
+
+func TestCarClassifyWithTransform(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errorcode":0,"car_coord":{"x":10,"y":10,"width":20,"height":5},
+			"logo_coord":[{"x":15,"y":12,"width":4,"height":2}]}`))
+	}))
+	defer srv.Close()
+
+	y := Init(AppSign{}, srv.URL)
+	img := ImageURL("http://example.com/crop.jpg").WithTransform(TranslateTransform(-100, -50))
+	rsp, err := y.CarClassifyImage(img, "")
+	if err != nil || rsp.Transform == nil {
+		t.Errorf("CarClassifyImage = %+v, %v\n", rsp, err)
+		return
+	}
+	o := rsp.Original()
+	if o.CarCoord != (CarCoordinate{X: 110, Y: 60, Width: 20, Height: 5}) || o.LogoCoord[0] != (CarCoordinate{X: 115, Y: 62, Width: 4, Height: 2}) {
+		t.Errorf("original car coords = %+v, %+v\n", o.CarCoord, o.LogoCoord)
+	}
+	if rsp.LogoCoord[0].X != 15 {
+		t.Errorf("Original modified the response\n")
+	}
+}
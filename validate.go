@@ -0,0 +1,212 @@
+/*
+* File Name:	validate.go
+* Description:  发送请求前的客户端校验: 参数、图片格式、大小和尺寸
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" //注册GIF格式, 用于读取尺寸
+	_ "image/jpeg"
+	_ "image/png"
+	"net/url"
+	"unicode"
+)
+
+const (
+	//IDMaxLen person_id, group_id和face_id的最大长度
+	IDMaxLen = 128
+)
+
+//ValidationError 请求参数不合法, 在签名和发送请求前返回
+type ValidationError struct {
+	Endpoint string //接口名, 如detectface
+	Field    string //参数名
+	Reason   string //原因
+}
+
+func (e *ValidationError) Error() string {
+	if e.Endpoint == "" {
+		return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("%s: invalid %s: %s", e.Endpoint, e.Field, e.Reason)
+}
+
+func invalid(field, reason string) error {
+	return &ValidationError{Field: field, Reason: reason}
+}
+
+//withEndpoint 为ValidationError补上接口名
+func withEndpoint(ifname string, err error) error {
+	if ve, ok := err.(*ValidationError); ok && ve.Endpoint == "" {
+		ve.Endpoint = ifname
+	}
+	return err
+}
+
+func validateRequest(ifname string, r interface{ Validate() error }) error {
+	return withEndpoint(ifname, r.Validate())
+}
+
+func requireID(field, id string) error {
+	if id == "" {
+		return invalid(field, "must not be empty")
+	}
+	if len(id) > IDMaxLen {
+		return invalid(field, fmt.Sprintf("longer than %d bytes", IDMaxLen))
+	}
+	for _, r := range id {
+		if unicode.IsControl(r) {
+			return invalid(field, "contains control characters")
+		}
+	}
+	return nil
+}
+
+func validID(ifname, field, id string) error {
+	return withEndpoint(ifname, requireID(field, id))
+}
+
+//ImageLimits 接口对图片的限制, 值为0的项不检查
+type ImageLimits struct {
+	MaxBytes  int //图片数据的最大字节数
+	MaxWidth  int //最大宽度(像素)
+	MaxHeight int //最大高度(像素)
+	MinWidth  int //最小宽度(像素)
+	MinHeight int //最小高度(像素)
+}
+
+//DefaultImageLimits 未单独设置的接口使用的限制
+var DefaultImageLimits = ImageLimits{
+	MaxBytes:  1 << 20,
+	MaxWidth:  4096,
+	MaxHeight: 4096,
+}
+
+//defaultEndpointLimits 各接口的默认限制, 人脸接口要求图片能容纳最小人脸
+var defaultEndpointLimits = map[string]ImageLimits{
+	"detectface":        {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+	"faceshape":         {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+	"facecompare":       {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+	"faceverify":        {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+	"faceidentify":      {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+	"multifaceidentify": {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+	"newperson":         {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+	"addface":           {MaxBytes: 1 << 20, MaxWidth: 4096, MaxHeight: 4096, MinWidth: 40, MinHeight: 40},
+}
+
+//SetImageLimits 设置接口ifname(如"detectface")的图片限制
+func (y *Youtu) SetImageLimits(ifname string, l ImageLimits) {
+	y.shared.mu.Lock()
+	if y.shared.limits == nil {
+		y.shared.limits = make(map[string]ImageLimits)
+	}
+	y.shared.limits[ifname] = l
+	y.shared.mu.Unlock()
+}
+
+//ImageLimitsFor 返回接口ifname的图片限制
+func (y *Youtu) ImageLimitsFor(ifname string) ImageLimits {
+	y.shared.mu.RLock()
+	l, ok := y.shared.limits[ifname]
+	y.shared.mu.RUnlock()
+	if ok {
+		return l
+	}
+	if l, ok := defaultEndpointLimits[ifname]; ok {
+		return l
+	}
+	return DefaultImageLimits
+}
+
+//图片格式
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+	FormatBMP  = "bmp"
+)
+
+//SniffImageFormat 根据文件头判断图片格式, 不支持的格式返回空串
+func SniffImageFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return FormatJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return FormatGIF
+	case bytes.HasPrefix(data, []byte("BM")) && len(data) >= 26:
+		return FormatBMP
+	}
+	return ""
+}
+
+//imageSize 返回图片的宽和高
+func imageSize(format string, data []byte) (width, height int, err error) {
+	if format == FormatBMP {
+		//与解码共用文件头解析, 支持OS/2格式
+		info, err := parseBMPHeader(data)
+		return info.width, info.height, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return cfg.Width, cfg.Height, err
+}
+
+//MaxDecodePixels 在本地解码的图片的最大像素数(宽*高), 防止很小的文件声明巨大的尺寸耗尽内存
+const MaxDecodePixels = 1 << 26
+
+//checkDecodePixels 完整解码前检查图片声明的尺寸
+func checkDecodePixels(w, h int) error {
+	if int64(w)*int64(h) > MaxDecodePixels {
+		return invalid("Image", fmt.Sprintf("%dx%d exceeds the decode limit of %d pixels", w, h, MaxDecodePixels))
+	}
+	return nil
+}
+
+//checkDecodeSize 按图片头中的尺寸检查像素数, 读不出尺寸的数据留给解码器报错
+func checkDecodeSize(data []byte) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	return checkDecodePixels(cfg.Width, cfg.Height)
+}
+
+//checkImageData 按接口限制检查图片数据
+func checkImageData(l ImageLimits, data []byte) error {
+	if len(data) == 0 {
+		return invalid("Image", "image data is empty")
+	}
+	if l.MaxBytes > 0 && len(data) > l.MaxBytes {
+		return invalid("Image", fmt.Sprintf("%d bytes exceeds the limit of %d bytes", len(data), l.MaxBytes))
+	}
+	format := SniffImageFormat(data)
+	if format == "" {
+		return invalid("Image", "not a JPEG, PNG, BMP or GIF image")
+	}
+	w, h, err := imageSize(format, data)
+	if err != nil || w <= 0 || h <= 0 {
+		return invalid("Image", "corrupted "+format+" image")
+	}
+	if (l.MaxWidth > 0 && w > l.MaxWidth) || (l.MaxHeight > 0 && h > l.MaxHeight) {
+		return invalid("Image", fmt.Sprintf("%dx%d exceeds the limit of %dx%d", w, h, l.MaxWidth, l.MaxHeight))
+	}
+	if w < l.MinWidth || h < l.MinHeight {
+		return invalid("Image", fmt.Sprintf("%dx%d is smaller than %dx%d", w, h, l.MinWidth, l.MinHeight))
+	}
+	return nil
+}
+
+//checkImageURL URL图片必须是http或https的绝对地址
+func checkImageURL(u string) error {
+	pu, err := url.Parse(u)
+	if err != nil || (pu.Scheme != "http" && pu.Scheme != "https") || pu.Host == "" {
+		return invalid("Image", "url must be an absolute http or https url")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+* File Name:	validate_test.go
+* Description:
+* Created:	2026-10-18
+ */
+
+package youtu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+//testBMP 生成只含文件头和信息头的24位BMP数据
+func testBMP(w, h int32) []byte {
+	b := make([]byte, 54)
+	copy(b, "BM")
+	binary.LittleEndian.PutUint32(b[14:], 40)
+	binary.LittleEndian.PutUint32(b[18:], uint32(w))
+	binary.LittleEndian.PutUint32(b[22:], uint32(h))
+	binary.LittleEndian.PutUint16(b[28:], 24)
+	return b
+}
+
+//testOS2BMP 生成只含文件头和OS/2信息头(BITMAPCOREHEADER)的24位BMP数据
+func testOS2BMP(w, h uint16) []byte {
+	b := make([]byte, 26)
+	copy(b, "BM")
+	binary.LittleEndian.PutUint32(b[14:], 12)
+	binary.LittleEndian.PutUint16(b[18:], w)
+	binary.LittleEndian.PutUint16(b[20:], h)
+	binary.LittleEndian.PutUint16(b[24:], 24)
+	return b
+}
+
+func TestSniffImageFormat(t *testing.T) {
+	var pngBuf, gifBuf bytes.Buffer
+	m := image.NewPaletted(image.Rect(0, 0, 50, 50), []color.Color{color.Black, color.White})
+	png.Encode(&pngBuf, m)
+	gif.Encode(&gifBuf, m, nil)
+
+	for _, c := range []struct {
+		data   []byte
+		format string
+	}{
+		{testJPEG(50, 50), FormatJPEG},
+		{pngBuf.Bytes(), FormatPNG},
+		{gifBuf.Bytes(), FormatGIF},
+		{testBMP(50, -50), FormatBMP},
+		{testOS2BMP(50, 60), FormatBMP},
+		{[]byte("%PDF-1.4"), ""},
+		{nil, ""},
+	} {
+		if f := SniffImageFormat(c.data); f != c.format {
+			t.Errorf("SniffImageFormat = %q, want %q\n", f, c.format)
+			continue
+		}
+		if c.format == "" {
+			continue
+		}
+		if err := checkImageData(ImageLimits{MinWidth: 40, MinHeight: 40}, c.data); err != nil {
+			t.Errorf("checkImageData(%s) = %v\n", c.format, err)
+		}
+	}
+	if w, h, err := imageSize(FormatBMP, testOS2BMP(50, 60)); w != 50 || h != 60 || err != nil {
+		t.Errorf("imageSize(OS/2 BMP) = %d, %d, %v, want 50, 60\n", w, h, err)
+	}
+}
+
+func TestImageValidation(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := newEchoServer(&bodies)
+	defer srv.Close()
+	as, _ := NewAppSign(1, "id", "key", "user")
+	y := Init(as, srv.URL)
+
+	for _, c := range []struct {
+		img    Image
+		reason string
+	}{
+		{ImageBytes(nil), "empty"},
+		{ImageBytes([]byte("plain text file")), "not a JPEG"},
+		{ImageBytes([]byte("\xff\xd8\xff\xe0 truncated")), "corrupted"},
+		{ImageBytes(testJPEG(20, 20)), "smaller than"},
+		{ImageBytes(testBMP(5000, 10)), "exceeds"},
+		{ImageURL("file:///etc/passwd"), "http"},
+	} {
+		_, err := y.DetectFaceImage(c.img, false)
+		ve, ok := err.(*ValidationError)
+		if !ok || ve.Endpoint != "detectface" || !strings.Contains(ve.Reason, c.reason) {
+			t.Errorf("DetectFaceImage err = %v, want ValidationError containing %q\n", err, c.reason)
+		}
+	}
+
+	//OCR接口没有最小尺寸限制
+	if _, err := y.GeneralOcrImage(ImageBytes(testJPEG(20, 20)), ""); err != nil {
+		t.Errorf("GeneralOcrImage failed: %s\n", err)
+	}
+	y.SetImageLimits("generalocr", ImageLimits{MaxBytes: 100})
+	if _, err := y.GeneralOcrImage(ImageBytes(testJPEG(20, 20)), ""); err == nil {
+		t.Errorf("GeneralOcrImage ignored SetImageLimits\n")
+	}
+
+	for _, err := range []error{
+		func() error { _, err := y.DelPerson(""); return err }(),
+		func() error { _, err := y.GetInfo(strings.Repeat("p", IDMaxLen+1)); return err }(),
+		func() error { _, err := y.DelFace("p", nil); return err }(),
+		func() error { _, err := y.GetFaceInfo("face\n1"); return err }(),
+	} {
+		if _, ok := err.(*ValidationError); !ok {
+			t.Errorf("err = %v, want ValidationError\n", err)
+		}
+	}
+	if len(bodies) != 1 {
+		t.Errorf("got %d requests, want only the valid GeneralOcrImage request\n", len(bodies))
+	}
+}
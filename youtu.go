@@ -8,7 +8,6 @@
 package youtu
 
 import (
-	"encoding/base64"
 	"errors"
 	"strconv"
 )
@@ -34,11 +33,11 @@ var (
 type AppSign struct {
 	appID     uint32 //接入优图服务时,生成的唯一id, 用于唯一标识接入业务
 	secretID  string //标识api鉴权调用者的密钥身份
-	secretKey string //用于加密签名字符串和服务器端验证签名字符串的密钥，secret_key 必须严格保管避免泄露
+	secretKey []byte //用于加密签名字符串和服务器端验证签名字符串的密钥，secret_key 必须严格保管避免泄露
 	userID    string //接入业务自行定义的用户id，用于唯一标识一个用户, 登陆开发者账号的QQ号码
 }
 
-//NewAppSign 新建应用签名
+//NewAppSign 新建应用签名, AppSign在格式化输出和日志中不会打印密钥
 func NewAppSign(appID uint32, secretID string, secretKey string, userID string) (as AppSign, err error) {
 	if len(userID) > UserIDMaxLen {
 		err = ErrUserIDTooLong
@@ -47,7 +46,7 @@ func NewAppSign(appID uint32, secretID string, secretKey string, userID string)
 	as = AppSign{
 		appID:     appID,
 		secretID:  secretID,
-		secretKey: secretKey,
+		secretKey: []byte(secretKey),
 		userID:    userID,
 	}
 	return
@@ -57,7 +56,9 @@ func NewAppSign(appID uint32, secretID string, secretKey string, userID string)
 type Youtu struct {
 	appSign AppSign
 	host    string
-	debug   bool //Default false
+	debug   bool    //Default false
+	tenant  string  //租户名, 默认租户为空
+	shared  *shared //同一客户端各租户共享的连接和统计
 }
 
 func (y *Youtu) appID() string {
@@ -70,6 +71,7 @@ func Init(appSign AppSign, host string) *Youtu {
 		appSign: appSign,
 		host:    host,
 		debug:   false,
+		shared:  newShared(),
 	}
 }
 
@@ -121,33 +123,42 @@ type Face struct {
 
 //DetectFaceRsp 脸检测返回
 type DetectFaceRsp struct {
-	SessionID   string `json:"session_id"`   //相应请求的session标识符，可用于结果查询
-	ImageWidth  int32  `json:"image_width"`  //请求图片的宽度
-	ImageHeight int32  `json:"image_height"` //请求图片的高度
-	Face        []Face `json:"face"`         //被检测出的人脸Face的列表
-	ErrorCode   int    `json:"errorcode"`    //返回状态值
-	ErrorMsg    string `json:"errormsg"`     //返回错误消息
+	SessionID   string     `json:"session_id"`   //相应请求的session标识符，可用于结果查询
+	ImageWidth  int32      `json:"image_width"`  //请求图片的宽度
+	ImageHeight int32      `json:"image_height"` //请求图片的高度
+	Face        []Face     `json:"face"`         //被检测出的人脸Face的列表
+	ErrorCode   int        `json:"errorcode"`    //返回状态值
+	ErrorMsg    string     `json:"errormsg"`     //返回错误消息
+	Transform   *Transform `json:"-"`            //原图到发送图片的变换, 见Original
 }
 
-//DetectFace 检测给定图片(Image)中的所有人脸(Face)的位置和相应的面部属性。
+//DetectFaceRequest 检测给定图片(Image)中的所有人脸(Face)的位置和相应的面部属性。
 //位置包括(x, y, w, h)，面部属性包括性别(gender), 年龄(age), 魅力值(beauty)
 //表情(expression), 眼镜(glass)和姿态(pitch，roll，yaw).
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) DetectFace(image []byte, isBigFace bool, imageType int) (rsp DetectFaceRsp, err error) {
+func (y *Youtu) DetectFaceRequest(r DetectFaceRequest) (rsp DetectFaceRsp, err error) {
+	if err = validateRequest("detectface", r); err != nil {
+		return
+	}
 	var req detectFaceReq
-	req.AppID = strconv.Itoa(int(y.appSign.appID))
-	req.Mode = mode(isBigFace)
+	req.AppID = y.appID()
+	req.Mode = mode(r.BigFace)
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("detectface", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("detectface", req, &rsp, 0)
+	rsp.Transform = t
 	return
 }
 
+//DetectFaceImage 同DetectFaceRequest
+func (y *Youtu) DetectFaceImage(image Image, isBigFace bool) (rsp DetectFaceRsp, err error) {
+	return y.DetectFaceRequest(DetectFaceRequest{Image: image, BigFace: isBigFace})
+}
+
 type faceShapeReq struct {
 	AppID string     `json:"app_id"`          //App的 API ID
 	Image string     `json:"image,omitempty"` //base64编码的二进制图片数据
@@ -179,25 +190,34 @@ type FaceShapeRsp struct {
 	ImageHeight int         `json:"image_height"` //请求图片的高度
 	ErrorCode   int         `json:"errorcode"`    //返回状态值
 	ErrorMsg    string      `json:"errormsg"`     //返回错误消息
+	Transform   *Transform  `json:"-"`            //原图到发送图片的变换, 见Original
 }
 
-//FaceShape 对请求图片进行五官定位，计算构成人脸轮廓的88个点，包括眉毛（左右各8点）、眼睛（左右各8点）、鼻子（13点）、嘴巴（22点）、脸型轮廓（21点）
-//imageType 表示image的类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) FaceShape(image []byte, isBigFace bool, imageType int) (rsp FaceShapeRsp, err error) {
+//FaceShapeRequest 对请求图片进行五官定位，计算构成人脸轮廓的88个点，包括眉毛（左右各8点）、眼睛（左右各8点）、鼻子（13点）、嘴巴（22点）、脸型轮廓（21点）
+func (y *Youtu) FaceShapeRequest(r FaceShapeRequest) (rsp FaceShapeRsp, err error) {
+	if err = validateRequest("faceshape", r); err != nil {
+		return
+	}
 	var req faceShapeReq
-	req.AppID = strconv.Itoa(int(y.appSign.appID))
-	req.Mode = mode(isBigFace)
+	req.AppID = y.appID()
+	req.Mode = mode(r.BigFace)
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("faceshape", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("faceshape", req, &rsp, 0)
+	rsp.Transform = t
 	return
 }
 
+//FaceShapeImage 同FaceShapeRequest
+func (y *Youtu) FaceShapeImage(image Image, isBigFace bool) (rsp FaceShapeRsp, err error) {
+	return y.FaceShapeRequest(FaceShapeRequest{Image: image, BigFace: isBigFace})
+}
+
 type faceCompareReq struct {
 	AppID  string `json:"app_id"`
 	ImageA string `json:"imageA,omitempty"` //使用base64编码的二进制图片数据A
@@ -214,24 +234,32 @@ type FaceCompareRsp struct {
 	ErrorMsg   string  `json:"errormsg"`   //返回错误消息
 }
 
-//FaceCompare 计算两个Face的相似性以及五官相似度
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) FaceCompare(imageA, imageB []byte, imageType int) (rsp FaceCompareRsp, err error) {
+//FaceCompareRequest 计算两个Face的相似性以及五官相似度
+func (y *Youtu) FaceCompareRequest(r FaceCompareRequest) (rsp FaceCompareRsp, err error) {
+	if err = validateRequest("facecompare", r); err != nil {
+		return
+	}
 	var req faceCompareReq
 	req.AppID = y.appID()
 
-	if imageType == 0 {
-		req.ImageA = base64.StdEncoding.EncodeToString(imageA)
-		req.ImageB = base64.StdEncoding.EncodeToString(imageB)
-	} else {
-		req.UrlA = string(imageA)
-		req.UrlB = string(imageB)
+	req.ImageA, req.UrlA, err = y.imageField("facecompare", r.ImageA)
+	if err != nil {
+		return
+	}
+	req.ImageB, req.UrlB, err = y.imageField("facecompare", r.ImageB)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("facecompare", req, &rsp, 0)
 	return
 }
 
+//FaceCompareImage 同FaceCompareRequest
+func (y *Youtu) FaceCompareImage(imageA, imageB Image) (rsp FaceCompareRsp, err error) {
+	return y.FaceCompareRequest(FaceCompareRequest{ImageA: imageA, ImageB: imageB})
+}
+
 type faceVerifyReq struct {
 	AppID    string `json:"app_id"`        //App的 API ID
 	Image    string `json:"image"`         //使用base64编码的二进制图片数据
@@ -248,23 +276,29 @@ type FaceVerifyRsp struct {
 	ErrorMsg   string  `json:"errormsg"`   //返回错误消息
 }
 
-//FaceVerify 给定一个Face和一个Person，返回是否是同一个人的判断以及信度。
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) FaceVerify(personID string, image []byte, imageType int) (rsp FaceVerifyRsp, err error) {
+//FaceVerifyRequest 给定一个Face和一个Person，返回是否是同一个人的判断以及信度。
+func (y *Youtu) FaceVerifyRequest(r FaceVerifyRequest) (rsp FaceVerifyRsp, err error) {
+	if err = validateRequest("faceverify", r); err != nil {
+		return
+	}
 	var req faceVerifyReq
 	req.AppID = y.appID()
-	req.PersonID = personID
+	req.PersonID = r.PersonID
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("faceverify", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("faceverify", req, &rsp, 0)
 	return
 }
 
+//FaceVerifyImage 同FaceVerifyRequest
+func (y *Youtu) FaceVerifyImage(personID string, image Image) (rsp FaceVerifyRsp, err error) {
+	return y.FaceVerifyRequest(FaceVerifyRequest{PersonID: personID, Image: image})
+}
+
 type faceIdentifyReq struct {
 	AppID   string `json:"app_id"`          //App的 API ID
 	GroupID string `json:"group_id"`        //候选人组id
@@ -287,23 +321,29 @@ type FaceIdentifyRsp struct {
 	ErrorMsg   string      `json:"errormsg"`   //返回错误消息
 }
 
-//FaceIdentify 对于一个待识别的人脸图片，在一个Group中识别出最相似的Person作为其身份返回
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) FaceIdentify(groupID string, image []byte, imageType int) (rsp FaceIdentifyRsp, err error) {
+//FaceIdentifyRequest 对于一个待识别的人脸图片，在一个Group中识别出最相似的Person作为其身份返回
+func (y *Youtu) FaceIdentifyRequest(r FaceIdentifyRequest) (rsp FaceIdentifyRsp, err error) {
+	if err = validateRequest("faceidentify", r); err != nil {
+		return
+	}
 	var req faceIdentifyReq
 	req.AppID = y.appID()
-	req.GroupID = groupID
+	req.GroupID = r.GroupID
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("faceidentify", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("faceidentify", req, &rsp, 0)
 	return
 }
 
+//FaceIdentifyImage 同FaceIdentifyRequest
+func (y *Youtu) FaceIdentifyImage(groupID string, image Image) (rsp FaceIdentifyRsp, err error) {
+	return y.FaceIdentifyRequest(FaceIdentifyRequest{GroupID: groupID, Image: image})
+}
+
 type MultiFaceIdentifyReq struct {
 	AppID    string   `json:"app_id"`   //App的 API ID
 	GroupID  string   `json:"group_id"` //候选人组id
@@ -334,37 +374,49 @@ type MultiFaceIdentifyRsp struct {
 	TimeMs    int                 `json:"time_ms"`
 	ErrorCode int                 `json:"errorcode"` //返回状态码
 	ErrorMsg  string              `json:"errormsg"`  //返回错误消息
+	Transform *Transform          `json:"-"`         //原图到发送图片的变换, 见Original
 }
 
-//MultiFaceIdentify 上传人脸图片，进行多人脸检索。
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) MultiFaceIdentify(groupID string, GroupIds []string, image []byte, imageType int, topn int, minSize int) (rsp MultiFaceIdentifyRsp, err error) {
+//MultiFaceIdentifyRequest 上传人脸图片，进行多人脸检索。
+func (y *Youtu) MultiFaceIdentifyRequest(r MultiFaceIdentifyRequest) (rsp MultiFaceIdentifyRsp, err error) {
+	if err = validateRequest("multifaceidentify", r); err != nil {
+		return
+	}
 	var req MultiFaceIdentifyReq
 	req.AppID = y.appID()
-
-	if groupID != "" {
-		req.GroupID = groupID
-	} else {
-		req.GroupIds = GroupIds
-	}
-
-	if topn == 0 {
-		req.Topn = 5
+	req.GroupID = r.GroupID
+	req.GroupIds = r.GroupIDs
+	req.Topn = r.TopN
+	if req.Topn == 0 {
+		req.Topn = DefaultTopN
 	}
-	if minSize == 0 {
-		req.MinSize = 40
+	req.MinSize = r.MinSize
+	if req.MinSize == 0 {
+		req.MinSize = DefaultMinFaceSize
 	}
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("multifaceidentify", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("multifaceidentify", req, &rsp, 0)
+	rsp.Transform = t
 	return
 }
 
+//MultiFaceIdentifyImage 同MultiFaceIdentifyRequest, groupID和GroupIds只能设置一个
+func (y *Youtu) MultiFaceIdentifyImage(groupID string, GroupIds []string, image Image, topn int, minSize int) (rsp MultiFaceIdentifyRsp, err error) {
+	return y.MultiFaceIdentifyRequest(MultiFaceIdentifyRequest{
+		GroupID:  groupID,
+		GroupIDs: GroupIds,
+		Image:    image,
+		TopN:     topn,
+		MinSize:  minSize,
+	})
+}
+
 type newPersonReq struct {
 	AppID      string   `json:"app_id"`          //App的 API ID
 	Image      string   `json:"image,omitempty"` //使用base64编码的二进制图片数据
@@ -387,26 +439,32 @@ type NewPersonRsp struct {
 	ErrorMsg  string   `json:"errormsg"`   //返回错误消息
 }
 
-//NewPerson 创建一个Person，并将Person放置到group_ids指定的组当中
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) NewPerson(personID string, personName string, groupIDs []string, image []byte, tag string, imageType int) (rsp NewPersonRsp, err error) {
+//NewPersonRequest 创建一个Person，并将Person放置到group_ids指定的组当中
+func (y *Youtu) NewPersonRequest(r NewPersonRequest) (rsp NewPersonRsp, err error) {
+	if err = validateRequest("newperson", r); err != nil {
+		return
+	}
 	var req newPersonReq
 	req.AppID = y.appID()
-	req.PersonID = personID
-	req.GroupIDs = groupIDs
-	req.PersonName = personName
-	req.Tag = tag
+	req.PersonID = r.PersonID
+	req.GroupIDs = r.GroupIDs
+	req.PersonName = r.PersonName
+	req.Tag = r.Tag
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("newperson", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("newperson", req, &rsp, 0)
 	return
 }
 
+//NewPersonImage 同NewPersonRequest
+func (y *Youtu) NewPersonImage(personID string, personName string, groupIDs []string, image Image, tag string) (rsp NewPersonRsp, err error) {
+	return y.NewPersonRequest(NewPersonRequest{PersonID: personID, PersonName: personName, GroupIDs: groupIDs, Image: image, Tag: tag})
+}
+
 type delPersonReq struct {
 	AppID    string `json:"app_id"`
 	PersonID string `json:"person_id"` //待删除个体ID
@@ -423,6 +481,9 @@ type DelPersonRsp struct {
 
 //DelPerson 删除一个Person
 func (y *Youtu) DelPerson(personID string) (rsp DelPersonRsp, err error) {
+	if err = validID("delperson", "PersonID", personID); err != nil {
+		return
+	}
 	req := delPersonReq{
 		AppID:    y.appID(),
 		PersonID: personID,
@@ -449,33 +510,38 @@ type AddFaceRsp struct {
 	ErrorMsg  string   `json:"errormsg"`   //返回错误消息
 }
 
-//AddFace 将一组Face加入到一个Person中。注意，一个Face只能被加入到一个Person中。
+//AddFaceRequest 将一组Face加入到一个Person中。注意，一个Face只能被加入到一个Person中。
 //一个Person最多允许包含10000个Face
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) AddFace(personID string, images [][]byte, tag string, imageType int) (rsp AddFaceRsp, err error) {
+func (y *Youtu) AddFaceRequest(r AddFaceRequest) (rsp AddFaceRsp, err error) {
+	if err = validateRequest("addface", r); err != nil {
+		return
+	}
 	var req addFaceReq
 	req.AppID = y.appID()
-	req.PersonID = personID
-	req.Tag = tag
+	req.PersonID = r.PersonID
+	req.Tag = r.Tag
 
-	imageDatas := make([]string, len(images))
-	if imageType == 0 {
-		for i, img := range images {
-			imageDatas[i] = base64.StdEncoding.EncodeToString([]byte(img))
+	for _, img := range r.Images {
+		data, url, err := y.imageField("addface", img)
+		if err != nil {
+			return rsp, err
 		}
-		req.Images = imageDatas
-
-	} else {
-		for i, img := range images {
-			imageDatas[i] = string([]byte(img))
+		if url != "" {
+			req.Urls = append(req.Urls, url)
+		} else {
+			req.Images = append(req.Images, data)
 		}
-		req.Urls = imageDatas
 	}
 
 	err = y.interfaceRequest("addface", req, &rsp, 0)
 	return
 }
 
+//AddFaceImages 同AddFaceRequest
+func (y *Youtu) AddFaceImages(personID string, images []Image, tag string) (rsp AddFaceRsp, err error) {
+	return y.AddFaceRequest(AddFaceRequest{PersonID: personID, Images: images, Tag: tag})
+}
+
 type delFaceReq struct {
 	AppID    string   `json:"app_id"`    //App的 API ID
 	PersonID string   `json:"person_id"` //待删除人脸的person ID
@@ -493,6 +559,13 @@ type DelFaceRsp struct {
 
 //DelFace 删除一个person下的face，包括特征，属性和face_id.
 func (y *Youtu) DelFace(personID string, faceIDs []string) (rsp DelFaceRsp, err error) {
+	if err = validID("delface", "PersonID", personID); err != nil {
+		return
+	}
+	if len(faceIDs) == 0 {
+		err = &ValidationError{Endpoint: "delface", Field: "FaceIDs", Reason: "at least one face id is required"}
+		return
+	}
 	req := delFaceReq{
 		AppID:    y.appID(),
 		PersonID: personID,
@@ -519,6 +592,9 @@ type SetInfoRsp struct {
 
 //SetInfo 设置Person的name.
 func (y *Youtu) SetInfo(personID string, personName string, tag string) (rsp SetInfoRsp, err error) {
+	if err = validID("setinfo", "PersonID", personID); err != nil {
+		return
+	}
 	req := setInfoReq{
 		AppID:      y.appID(),
 		PersonID:   personID,
@@ -547,6 +623,9 @@ type GetInfoRsp struct {
 
 //GetInfo 获取一个Person的信息, 包括name, id, tag, 相关的face, 以及groups等信息。
 func (y *Youtu) GetInfo(personID string) (rsp GetInfoRsp, err error) {
+	if err = validID("getinfo", "PersonID", personID); err != nil {
+		return
+	}
 	req := getInfoReq{
 		AppID:    y.appID(),
 		PersonID: personID,
@@ -589,6 +668,9 @@ type GetPersonIDsRsp struct {
 
 //GetPersonIDs 获取一个组Group中所有person列表
 func (y *Youtu) GetPersonIDs(groupID string) (rsp GetPersonIDsRsp, err error) {
+	if err = validID("getpersonids", "GroupID", groupID); err != nil {
+		return
+	}
 	req := getPersonIDsReq{
 		AppID:   y.appID(),
 		GroupID: groupID,
@@ -611,6 +693,9 @@ type GetFaceIDsRsp struct {
 
 //GetFaceIDs 获取一个组person中所有face列表
 func (y *Youtu) GetFaceIDs(personID string) (rsp GetFaceIDsRsp, err error) {
+	if err = validID("getfaceids", "PersonID", personID); err != nil {
+		return
+	}
 	req := getFaceIDsReq{
 		AppID:    y.appID(),
 		PersonID: personID,
@@ -633,6 +718,9 @@ type GetFaceInfoRsp struct {
 
 //GetFaceInfo 获取一个face的相关特征信息
 func (y *Youtu) GetFaceInfo(faceID string) (rsp GetFaceInfoRsp, err error) {
+	if err = validID("getfaceinfo", "FaceID", faceID); err != nil {
+		return
+	}
 	req := getFaceInfoReq{
 		AppID:  y.appID(),
 		FaceID: faceID,
@@ -655,22 +743,28 @@ type FuzzyDetectRsp struct {
 	ErrorMsg        string  `json:"errormsg"`         //返回错误消息
 }
 
-//FuzzyDetect 检测图片的模糊度
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) FuzzyDetect(image []byte, imageType int, seq string) (rsp FuzzyDetectRsp, err error) {
+//FuzzyDetectRequest 检测图片的模糊度
+func (y *Youtu) FuzzyDetectRequest(r FuzzyDetectRequest) (rsp FuzzyDetectRsp, err error) {
+	if err = validateRequest("fuzzydetect", r); err != nil {
+		return
+	}
 	var req FuzzyDetectReq
 	req.AppID = y.appID()
-	req.Seq = seq
+	req.Seq = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("fuzzydetect", r.Image)
+	if err != nil {
+		return
 	}
 	err = y.interfaceRequest("fuzzydetect", req, &rsp, 1)
 	return
 }
 
+//FuzzyDetectImage 同FuzzyDetectRequest
+func (y *Youtu) FuzzyDetectImage(image Image, seq string) (rsp FuzzyDetectRsp, err error) {
+	return y.FuzzyDetectRequest(FuzzyDetectRequest{Image: image, Seq: seq})
+}
+
 type FoodDetectReq struct {
 	AppID string `json:"app_id"`          //App的 API ID
 	Url   string `json:"url,omitempty"`   //图片的url
@@ -685,22 +779,28 @@ type FoodDetectRsp struct {
 	ErrorMsg       string  `json:"errormsg"`  //返回错误消息
 }
 
-//FoodDetect 美食检测
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) FoodDetect(image []byte, imageType int, seq string) (rsp FoodDetectRsp, err error) {
+//FoodDetectRequest 美食检测
+func (y *Youtu) FoodDetectRequest(r FoodDetectRequest) (rsp FoodDetectRsp, err error) {
+	if err = validateRequest("fooddetect", r); err != nil {
+		return
+	}
 	var req FoodDetectReq
 	req.AppID = y.appID()
-	req.Seq = seq
+	req.Seq = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("fooddetect", r.Image)
+	if err != nil {
+		return
 	}
 	err = y.interfaceRequest("fooddetect", req, &rsp, 1)
 	return
 }
 
+//FoodDetectImage 同FoodDetectRequest
+func (y *Youtu) FoodDetectImage(image Image, seq string) (rsp FoodDetectRsp, err error) {
+	return y.FoodDetectRequest(FoodDetectRequest{Image: image, Seq: seq})
+}
+
 type ImageTagReq struct {
 	AppID string `json:"app_id"`          //App的 API ID
 	Url   string `json:"url,omitempty"`   //图片的url
@@ -720,23 +820,29 @@ type ImageTagRsp struct {
 	ErrorMsg  string     `json:"errormsg"`  //返回错误消息
 }
 
-//ImageTag 图片分类
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) ImageTag(image []byte, imageType int, seq string) (rsp ImageTagRsp, err error) {
+//ImageTagRequest 图片分类
+func (y *Youtu) ImageTagRequest(r ImageTagRequest) (rsp ImageTagRsp, err error) {
+	if err = validateRequest("imagetag", r); err != nil {
+		return
+	}
 	var req ImageTagReq
 	req.AppID = y.appID()
-	req.Seq = seq
+	req.Seq = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("imagetag", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("imagetag", req, &rsp, 1)
 	return
 }
 
+//ImageTagImage 同ImageTagRequest
+func (y *Youtu) ImageTagImage(image Image, seq string) (rsp ImageTagRsp, err error) {
+	return y.ImageTagRequest(ImageTagRequest{Image: image, Seq: seq})
+}
+
 type ImagePornReq struct {
 	AppID string `json:"app_id"`          //App的 API ID
 	Url   string `json:"url,omitempty"`   //图片的url
@@ -751,23 +857,29 @@ type ImagePornRsp struct {
 	ErrorMsg  string     `json:"errormsg"`  //返回错误消息
 }
 
-//ImagePorn 图片鉴黄
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) ImagePorn(image []byte, imageType int, seq string) (rsp ImagePornRsp, err error) {
+//ImagePornRequest 图片鉴黄
+func (y *Youtu) ImagePornRequest(r ImagePornRequest) (rsp ImagePornRsp, err error) {
+	if err = validateRequest("imageporn", r); err != nil {
+		return
+	}
 	var req ImagePornReq
 	req.AppID = y.appID()
-	req.Seq = seq
+	req.Seq = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("imageporn", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("imageporn", req, &rsp, 1)
 	return
 }
 
+//ImagePornImage 同ImagePornRequest
+func (y *Youtu) ImagePornImage(image Image, seq string) (rsp ImagePornRsp, err error) {
+	return y.ImagePornRequest(ImagePornRequest{Image: image, Seq: seq})
+}
+
 type ImageTerrorismReq struct {
 	AppID string `json:"app_id"`          //App的 API ID
 	Url   string `json:"url,omitempty"`   //图片的url
@@ -782,23 +894,29 @@ type ImageTerrorismRsp struct {
 	ErrorMsg  string     `json:"errormsg"`  //返回错误消息
 }
 
-//ImageTerrorism 图片暴恐检测
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) ImageTerrorism(image []byte, imageType int, seq string) (rsp ImagePornRsp, err error) {
+//ImageTerrorismRequest 图片暴恐检测
+func (y *Youtu) ImageTerrorismRequest(r ImageTerrorismRequest) (rsp ImagePornRsp, err error) {
+	if err = validateRequest("imageterrorism", r); err != nil {
+		return
+	}
 	var req ImagePornReq
 	req.AppID = y.appID()
-	req.Seq = seq
+	req.Seq = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("imageterrorism", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("imageterrorism", req, &rsp, 1)
 	return
 }
 
+//ImageTerrorismImage 同ImageTerrorismRequest
+func (y *Youtu) ImageTerrorismImage(image Image, seq string) (rsp ImagePornRsp, err error) {
+	return y.ImageTerrorismRequest(ImageTerrorismRequest{Image: image, Seq: seq})
+}
+
 type CarClassifyReq struct {
 	AppID     string `json:"app_id"`          //App的 API ID
 	Url       string `json:"url,omitempty"`   //图片的url
@@ -824,31 +942,40 @@ type CarTag struct {
 }
 
 type CarClassifyRsp struct {
-	SessionId string        `json:"session_id,omitempty"` // 序列号
-	CarCoord  CarCoordinate `json:"car_coord"`
+	SessionId string          `json:"session_id,omitempty"` // 序列号
+	CarCoord  CarCoordinate   `json:"car_coord"`
 	LogoCoord []CarCoordinate `json:"logo_coord"`
-	Tags      []CarTag      `json:"tags"`
-	ErrorCode int32         `json:"errorcode"` //返回状态码
-	ErrorMsg  string        `json:"errormsg"`  //返回错误消息
+	Tags      []CarTag        `json:"tags"`
+	ErrorCode int32           `json:"errorcode"` //返回状态码
+	ErrorMsg  string          `json:"errormsg"`  //返回错误消息
+	Transform *Transform      `json:"-"`         //原图到发送图片的变换, 见Original
 }
 
-//CarClassify 车辆属性识别
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) CarClassify(image []byte, imageType int, session_id string) (rsp CarClassifyRsp, err error) {
+//CarClassifyRequest 车辆属性识别
+func (y *Youtu) CarClassifyRequest(r CarClassifyRequest) (rsp CarClassifyRsp, err error) {
+	if err = validateRequest("carclassify", r); err != nil {
+		return
+	}
 	var req CarClassifyReq
 	req.AppID = y.appID()
-	req.SessionId = session_id
+	req.SessionId = r.SessionID
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("carclassify", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("carclassify", req, &rsp, 3)
+	rsp.Transform = t
 	return
 }
 
+//CarClassifyImage 同CarClassifyRequest
+func (y *Youtu) CarClassifyImage(image Image, session_id string) (rsp CarClassifyRsp, err error) {
+	return y.CarClassifyRequest(CarClassifyRequest{Image: image, SessionID: session_id})
+}
+
 type IdcardOcrReq struct {
 	AppID     string `json:"app_id"`               //App的 API ID
 	Url       string `json:"url,omitempty"`        //图片的url
@@ -887,25 +1014,31 @@ type IdcardOcrRsp struct {
 	ErrorMsg                string   `json:"errormsg"`                            //返回错误消息
 }
 
-//IdcardOcr 图片分类
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//IdcardOcrRequest 图片分类
 //cardType 代表身份证正面还是反面，其中0代表正面，1代表反面
-func (y *Youtu) IdcardOcr(image []byte, imageType int, cardType int32, seq string) (rsp IdcardOcrRsp, err error) {
+func (y *Youtu) IdcardOcrRequest(r IdcardOcrRequest) (rsp IdcardOcrRsp, err error) {
+	if err = validateRequest("idcardocr", r); err != nil {
+		return
+	}
 	var req IdcardOcrReq
 	req.AppID = y.appID()
-	req.SessionId = seq
-	req.CardType = cardType
+	req.SessionId = r.Seq
+	req.CardType = r.CardType
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	req.Image, req.Url, err = y.imageField("idcardocr", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("idcardocr", req, &rsp, 2)
 	return
 }
 
+//IdcardOcrImage 同IdcardOcrRequest
+func (y *Youtu) IdcardOcrImage(image Image, cardType int32, seq string) (rsp IdcardOcrRsp, err error) {
+	return y.IdcardOcrRequest(IdcardOcrRequest{Image: image, CardType: cardType, Seq: seq})
+}
+
 type Coordinate struct {
 	X      int32 `json:"x"`
 	Y      int32 `json:"y"`
@@ -941,27 +1074,36 @@ type DriverlicenseOcrRsp struct {
 	Items     []ItemContent `json:"items,omitempty"`
 	ErrorCode int32         `json:"errorcode"` //返回状态码
 	ErrorMsg  string        `json:"errormsg"`  //返回错误消息
+	Transform *Transform    `json:"-"`         //原图到发送图片的变换, 见Original
 }
 
-//DriverLicenseOcr 行驶证&驾驶证识别
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
+//DriverLicenseOcrRequest 行驶证&驾驶证识别
 //procType 表示图片识别类型，其中0代表行驶证，1代表驾驶证
-func (y *Youtu) DriverLicenseOcr(image []byte, imageType int, procType int32, seq string) (rsp DriverlicenseOcrRsp, err error) {
+func (y *Youtu) DriverLicenseOcrRequest(r DriverLicenseOcrRequest) (rsp DriverlicenseOcrRsp, err error) {
+	if err = validateRequest("driverlicenseocr", r); err != nil {
+		return
+	}
 	var req DriverlicenseOcrReq
 	req.AppID = y.appID()
-	req.SessionId = seq
-	req.Type = procType
+	req.SessionId = r.Seq
+	req.Type = r.Type
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("driverlicenseocr", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("driverlicenseocr", req, &rsp, 2)
+	rsp.Transform = t
 	return
 }
 
+//DriverLicenseOcrImage 同DriverLicenseOcrRequest
+func (y *Youtu) DriverLicenseOcrImage(image Image, procType int32, seq string) (rsp DriverlicenseOcrRsp, err error) {
+	return y.DriverLicenseOcrRequest(DriverLicenseOcrRequest{Image: image, Type: procType, Seq: seq})
+}
+
 type BCOcrReq struct {
 	AppID     string `json:"app_id"`               //App的 API ID
 	Url       string `json:"url,omitempty"`        //图片的url
@@ -974,25 +1116,34 @@ type BCOcrRsp struct {
 	Items     []ItemContent `json:"items,omitempty"`
 	ErrorCode int32         `json:"errorcode"` //返回状态码
 	ErrorMsg  string        `json:"errormsg"`  //返回错误消息
+	Transform *Transform    `json:"-"`         //原图到发送图片的变换, 见Original
 }
 
-//BCOcr 名片OCR识别
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) BCOcr(image []byte, imageType int, seq string) (rsp BCOcrRsp, err error) {
+//BCOcrRequest 名片OCR识别
+func (y *Youtu) BCOcrRequest(r BCOcrRequest) (rsp BCOcrRsp, err error) {
+	if err = validateRequest("bcocr", r); err != nil {
+		return
+	}
 	var req BCOcrReq
 	req.AppID = y.appID()
-	req.SessionId = seq
+	req.SessionId = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("bcocr", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("bcocr", req, &rsp, 2)
+	rsp.Transform = t
 	return
 }
 
+//BCOcrImage 同BCOcrRequest
+func (y *Youtu) BCOcrImage(image Image, seq string) (rsp BCOcrRsp, err error) {
+	return y.BCOcrRequest(BCOcrRequest{Image: image, Seq: seq})
+}
+
 type GeneralOcrReq struct {
 	AppID     string `json:"app_id"`               //App的 API ID
 	Url       string `json:"url,omitempty"`        //图片的url
@@ -1005,72 +1156,105 @@ type GeneralOcrRsp struct {
 	Items     []ItemContent `json:"items,omitempty"`
 	ErrorCode int32         `json:"errorcode"` //返回状态码
 	ErrorMsg  string        `json:"errormsg"`  //返回错误消息
+	Transform *Transform    `json:"-"`         //原图到发送图片的变换, 见Original
 }
 
-//GeneralOcr 通用OCR识别
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) GeneralOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+//GeneralOcrRequest 通用OCR识别
+func (y *Youtu) GeneralOcrRequest(r GeneralOcrRequest) (rsp GeneralOcrRsp, err error) {
+	if err = validateRequest("generalocr", r); err != nil {
+		return
+	}
 	var req GeneralOcrReq
 	req.AppID = y.appID()
-	req.SessionId = seq
+	req.SessionId = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("generalocr", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("generalocr", req, &rsp, 2)
+	rsp.Transform = t
 	return
 }
 
-//CreditCardOcr 银行卡OCR识别
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) CreditCardOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+//GeneralOcrImage 同GeneralOcrRequest
+func (y *Youtu) GeneralOcrImage(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.GeneralOcrRequest(GeneralOcrRequest{Image: image, Seq: seq})
+}
+
+//CreditCardOcrRequest 银行卡OCR识别
+func (y *Youtu) CreditCardOcrRequest(r CreditCardOcrRequest) (rsp GeneralOcrRsp, err error) {
+	if err = validateRequest("creditcardocr", r); err != nil {
+		return
+	}
 	var req GeneralOcrReq
 	req.AppID = y.appID()
-	req.SessionId = seq
+	req.SessionId = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("creditcardocr", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("creditcardocr", req, &rsp, 2)
+	rsp.Transform = t
 	return
 }
 
-//BizLicenseOcr 营业执照OCR识别
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) BizLicenseOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+//CreditCardOcrImage 同CreditCardOcrRequest
+func (y *Youtu) CreditCardOcrImage(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.CreditCardOcrRequest(CreditCardOcrRequest{Image: image, Seq: seq})
+}
+
+//BizLicenseOcrRequest 营业执照OCR识别
+func (y *Youtu) BizLicenseOcrRequest(r BizLicenseOcrRequest) (rsp GeneralOcrRsp, err error) {
+	if err = validateRequest("bizlicenseocr", r); err != nil {
+		return
+	}
 	var req GeneralOcrReq
 	req.AppID = y.appID()
-	req.SessionId = seq
+	req.SessionId = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("bizlicenseocr", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("bizlicenseocr", req, &rsp, 2)
+	rsp.Transform = t
 	return
 }
 
-//PlateOcr 车牌OCR识别
-//imageType 表示image类型是图片还是URL, 其中0代表图片,1代表url
-func (y *Youtu) PlateOcr(image []byte, imageType int, seq string) (rsp GeneralOcrRsp, err error) {
+//BizLicenseOcrImage 同BizLicenseOcrRequest
+func (y *Youtu) BizLicenseOcrImage(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.BizLicenseOcrRequest(BizLicenseOcrRequest{Image: image, Seq: seq})
+}
+
+//PlateOcrRequest 车牌OCR识别
+func (y *Youtu) PlateOcrRequest(r PlateOcrRequest) (rsp GeneralOcrRsp, err error) {
+	if err = validateRequest("plateocr", r); err != nil {
+		return
+	}
 	var req GeneralOcrReq
 	req.AppID = y.appID()
-	req.SessionId = seq
+	req.SessionId = r.Seq
 
-	if imageType == 0 {
-		req.Image = base64.StdEncoding.EncodeToString(image)
-	} else {
-		req.Url = string(image)
+	var t *Transform
+	req.Image, req.Url, t, err = y.encodeImage("plateocr", r.Image)
+	if err != nil {
+		return
 	}
 
 	err = y.interfaceRequest("plateocr", req, &rsp, 2)
+	rsp.Transform = t
 	return
 }
+
+//PlateOcrImage 同PlateOcrRequest
+func (y *Youtu) PlateOcrImage(image Image, seq string) (rsp GeneralOcrRsp, err error) {
+	return y.PlateOcrRequest(PlateOcrRequest{Image: image, Seq: seq})
+}
@@ -17,7 +17,7 @@ import (
 var as = AppSign{
 	appID:     0,
 	secretID:  "",
-	secretKey: "",
+	secretKey: []byte(""),
 	userID:    "",
 }
 